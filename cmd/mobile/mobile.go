@@ -1,11 +1,13 @@
 package mobile
 
 import (
+	"time"
+
 	"github/brensch/game/pkg/game"
 
 	"github.com/hajimehoshi/ebiten/v2/mobile"
 )
 
 func InitGame(w, h int) {
-	mobile.SetGame(game.NewGame(w, h))
+	mobile.SetGame(game.NewGame(w, h, time.Now().UnixNano(), nil))
 }
@@ -1,14 +1,45 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"time"
 
 	"github/brensch/game/pkg/game"
+	"github/brensch/game/pkg/sim"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	seed := flag.Int64("seed", 0, "seed for the game's random number generator (picked from the clock if not set)")
+	modsDir := flag.String("mods", "", "directory of machine mod definitions (*.json) to load")
+	flag.Parse()
+	seedSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			seedSet = true
+		}
+	})
+	if !seedSet {
+		*seed = time.Now().UnixNano()
+	}
+	// Print the seed so the game can be replayed with -seed
+	log.Printf("seed %d", *seed)
+
+	var mods []*sim.Mod
+	if *modsDir != "" {
+		var err error
+		mods, err = sim.LoadMods(*modsDir)
+		if err != nil {
+			// Broken mods are skipped rather than stopping the game.
+			log.Printf("some mods were not loaded:\n%v", err)
+		}
+		for _, mod := range mods {
+			log.Printf("loaded mod %s from %s", mod.Spec.Name, mod.File)
+		}
+	}
+
 	ebiten.SetWindowSize(480, 800)
 	ebiten.SetWindowTitle("Factory game")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
@@ -20,7 +51,7 @@ func main() {
 		ebiten.SetMonitor(monitors[1])
 	}
 
-	g := game.NewGame(480, 800)
+	g := game.NewGame(480, 800, *seed, mods)
 
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"image/color"
+	"math/big"
+	"math/rand"
+
+	"github/brensch/game/pkg/sim"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -121,43 +125,33 @@ func (b *Button) HandleClick(g *Game, input InputState) {
 
 // Button click handlers
 func handleRestartClick(g *Game, input InputState) {
-	// Reset game state
+	// Reset game state, seeding the new game from the old one so a whole
+	// session is reproducible from the first seed
+	seed := g.state.rng.Int63()
 	g.state = &GameState{
-		phase:          PhaseBuild,
-		money:          10,
-		runsLeft:       6,
-		machines:       make([]*MachineState, gridCols*gridRows),
-		round:          1,
-		animations:     []*Animation{},
-		animationTick:  0,
-		animationSpeed: 1.0,
-		buttons:        make(map[string]*Button),
-		allChanges:     nil,
-		multiplier:     1,
-		multMult:       1,
-		roundScore:     0,
-		totalScore:     0,
-		targetScore:    10,
-		gameOver:       false,
-		endRunDelay:    0,
-		previousPhase:  PhaseBuild,
+		phase:           PhaseBuild,
+		money:           10,
+		runsLeft:        6,
+		machines:        make([]*MachineState, gridCols*gridRows),
+		round:           1,
+		animations:      []*Animation{},
+		animationTick:   0,
+		animationSpeed:  1.0,
+		buttons:         make(map[string]*Button),
+		allChanges:      nil,
+		multiplier:      big.NewInt(1),
+		roundScore:      big.NewInt(0),
+		totalScore:      big.NewInt(0),
+		targetScore:     roundTarget(1),
+		gameOver:        false,
+		endRunDelay:     0,
+		previousPhase:   PhaseBuild,
+		selectedForeman: -1,
+		seed:            seed,
+		rng:             rand.New(rand.NewSource(seed)),
 	}
 	g.initButtons()
-	g.state.catalogue = []MachineInterface{
-		&Conveyor{},
-		&Processor{},
-		&Miner{},
-		&Splitter{},
-		&GeneralConsumer{},
-		&Amplifier{},
-		&Combiner{},
-		&Booster{},
-		&Catalyst{},
-	}
-	g.state.inventorySize = 5
-	g.state.restocksLeft = 3
-	g.state.inventory = dealMachines(g.state.catalogue, 5, 6)
-	g.state.inventorySelected = make([]bool, len(g.state.inventory))
+	g.state.dealStartingDeck(g.mods)
 }
 
 func handleRunClick(g *Game, input InputState) {
@@ -165,12 +159,26 @@ func handleRunClick(g *Game, input InputState) {
 		g.state.phase = PhaseRun
 		g.state.allChanges = nil
 		g.state.animations = []*Animation{}
+		g.state.objectPositions = map[int][2]float64{}
+		g.state.leftovers = nil
+		g.state.runError = nil
 		g.state.animationTick = 0
 		g.state.animationSpeed = 1.0
 		g.state.endRunDelay = 0
+		// The run is simulated on a copy of the machines, since they are
+		// drawn while it runs
+		machines := sim.CloneMachines(g.state.simMachines())
+		seed := g.state.rng.Int63()
+		opts := sim.RunOptions{
+			Hand:    append([]*sim.Object{}, g.state.objectDeck.Hand...),
+			Foremen: g.state.simForemen(),
+		}
+		g.state.selectedForeman = -1
+		results := make(chan runResult, 1)
+		g.state.runResults = results
 		go func() {
-			changes, _ := SimulateRun(g.state.machines)
-			g.state.allChanges = changes
+			changes, err := sim.SimulateRunWith(machines, seed, opts)
+			results <- runResult{machines: machines, changes: changes, total: sim.ScoreRun(changes, opts.Foremen), err: err}
 		}()
 	}
 }
@@ -178,15 +186,7 @@ func handleRunClick(g *Game, input InputState) {
 func handleSellClick(g *Game, input InputState) {
 	selected := g.getSelectedMachine()
 	if selected != nil && selected.IsPlaced {
-		// Remove from grid
-		for pos, ms := range g.state.machines {
-			if ms == selected {
-				g.state.machines[pos] = nil
-				break
-			}
-		}
-		// Deselect
-		selected.Selected = false
+		g.state.sellMachine(selected)
 	}
 }
 
@@ -196,19 +196,19 @@ func handleNextRoundClick(g *Game, input InputState) {
 		g.state.phase = PhaseBuild
 		g.state.runsLeft = 6
 		g.state.round++
-		g.state.targetScore = g.state.round * g.state.round * 10
-		g.state.money += g.state.round * 10
-		// Reset machines
-		g.state.machines = make([]*MachineState, gridCols*gridRows)
-		// Reset available machines
-		g.state.inventory = dealMachines(g.state.catalogue, g.state.inventorySize, g.state.runsLeft)
+		g.state.targetScore = roundTarget(g.state.round)
+		g.state.earnings = RoundEarnings{}
+		g.state.rewards = nil
+		// Return last round's machines to the discard pile and deal new ones
+		g.state.discardBoard()
+		g.state.inventory = dealMachines(g.state.deck, g.state.rng, g.state.inventorySize, g.state.runsLeft)
 		g.state.inventorySelected = make([]bool, len(g.state.inventory))
-		g.state.restocksLeft = 3
+		g.state.openShop()
 	}
 }
 
 func handleInfoClick(g *Game, input InputState) {
-	if g.state.phase == PhaseBuild || g.state.phase == PhaseRoundEnd {
+	if g.state.phase == PhaseBuild || g.state.phase == PhaseRoundEnd || g.state.phase == PhaseShop {
 		g.state.previousPhase = g.state.phase
 		g.state.phase = PhaseInfo
 	}
@@ -220,45 +220,24 @@ func handleCloseInfoClick(g *Game, input InputState) {
 
 func handleRotateLeftClick(g *Game, input InputState) {
 	fmt.Println("Rotate Left Clicked")
-	selected := g.getSelectedMachine()
-	if selected != nil {
-		selected.Orientation = (selected.Orientation + 3) % 4
-	}
+	rotateSelected(g, 3)
 }
 
 func handleRotateRightClick(g *Game, input InputState) {
-	selected := g.getSelectedMachine()
-	if selected != nil {
-		selected.Orientation = (selected.Orientation + 1) % 4
-	}
+	rotateSelected(g, 1)
 }
 
-func handleRestockClick(g *Game, input InputState) {
-	if g.state.restocksLeft > 0 {
-		selectedIndices := []int{}
-		for i, sel := range g.state.inventorySelected {
-			if sel {
-				selectedIndices = append(selectedIndices, i)
-			}
-		}
-		num := len(selectedIndices)
-		if num > 0 {
-			// Discard selected
-			newInventory := []*MachineState{}
-			newSelected := []bool{}
-			for i, ms := range g.state.inventory {
-				if !g.state.inventorySelected[i] {
-					newInventory = append(newInventory, ms)
-					newSelected = append(newSelected, false)
-				}
-			}
-			g.state.inventory = newInventory
-			g.state.inventorySelected = newSelected
-			// Deal num new
-			newMachines := dealMachines(g.state.catalogue, num, g.state.runsLeft)
-			g.state.inventory = append(g.state.inventory, newMachines...)
-			g.state.inventorySelected = append(g.state.inventorySelected, make([]bool, num)...)
-			g.state.restocksLeft--
-		}
+// rotateSelected turns the selected machine clockwise by quarter turns. A
+// placed machine turns as a unit about its top-left cell, and does not turn
+// if its new footprint would not fit.
+func rotateSelected(g *Game, quarterTurns int) {
+	selected := g.getSelectedMachine()
+	if selected == nil {
+		return
+	}
+	previous := selected.Orientation
+	selected.Orientation = (selected.Orientation + sim.Orientation(quarterTurns)) % 4
+	if selected.IsPlaced && !g.state.placeMachine(selected, g.getPos(selected)) {
+		selected.Orientation = previous
 	}
 }
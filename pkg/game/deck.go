@@ -0,0 +1,215 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github/brensch/game/pkg/sim"
+)
+
+const (
+	startingDeckName = "standard" // Deck in decks.json every game starts with
+	startingHandSize = 5          // Machines dealt at the start of a game
+	startingRunsLeft = 6          // Runs in each round
+)
+
+// decksJSON defines the starting decks by name. Each entry names a machine by
+// its registered key, and may leave out count to use the machine's default.
+//
+//go:embed decks.json
+var decksJSON []byte
+
+// deckEntry is one line of a starting deck definition.
+type deckEntry struct {
+	Machine string `json:"machine"`
+	Count   *int   `json:"count"`
+}
+
+// startingDeck returns the machines in the named deck from decks.json.
+func startingDeck(name string) ([]sim.MachineInterface, error) {
+	var decks map[string][]deckEntry
+	if err := json.Unmarshal(decksJSON, &decks); err != nil {
+		return nil, fmt.Errorf("reading decks.json: %w", err)
+	}
+	entries, ok := decks[name]
+	if !ok {
+		return nil, fmt.Errorf("no deck named %q in decks.json", name)
+	}
+	var machines []sim.MachineInterface
+	for _, entry := range entries {
+		spec, ok := sim.SpecByKey(entry.Machine)
+		if !ok {
+			return nil, fmt.Errorf("deck %q: unknown machine %q", name, entry.Machine)
+		}
+		count := spec.DefaultCount
+		if entry.Count != nil {
+			count = *entry.Count
+		}
+		for i := 0; i < count; i++ {
+			machines = append(machines, spec.Machine)
+		}
+	}
+	return machines, nil
+}
+
+// Card is one card in the machine deck. Placed and inventory machines remember
+// the card they were dealt from so it can go back to the discard pile.
+type Card struct {
+	Machine sim.MachineInterface
+}
+
+// Deck is the player's machine deck. Every card is always in exactly one of
+// the draw pile, the discard pile or play, which covers the inventory, the
+// grid and the shop shelf. The top of the draw pile is the end of DrawPile.
+type Deck struct {
+	DrawPile    []*Card
+	DiscardPile []*Card
+	InPlay      []*Card
+}
+
+// newDeck returns a shuffled deck holding one card for each machine.
+func newDeck(rng *rand.Rand, machines []sim.MachineInterface) *Deck {
+	d := &Deck{}
+	for _, machine := range machines {
+		d.DrawPile = append(d.DrawPile, &Card{Machine: machine})
+	}
+	shuffleCards(rng, d.DrawPile)
+	return d
+}
+
+// draw puts the top card of the draw pile into play and returns it. When the
+// draw pile runs out the discard pile is shuffled to form a new one. It
+// returns nil if both piles are empty.
+func (d *Deck) draw(rng *rand.Rand) *Card {
+	if len(d.DrawPile) == 0 {
+		d.DrawPile, d.DiscardPile = d.DiscardPile, nil
+		shuffleCards(rng, d.DrawPile)
+	}
+	if len(d.DrawPile) == 0 {
+		return nil
+	}
+	card := d.DrawPile[len(d.DrawPile)-1]
+	d.DrawPile = d.DrawPile[:len(d.DrawPile)-1]
+	d.InPlay = append(d.InPlay, card)
+	return card
+}
+
+// discard takes a card out of play and puts it on the discard pile. Cards that
+// are not in play are ignored.
+func (d *Deck) discard(card *Card) {
+	for i, c := range d.InPlay {
+		if c == card {
+			d.InPlay = append(d.InPlay[:i:i], d.InPlay[i+1:]...)
+			d.DiscardPile = append(d.DiscardPile, card)
+			return
+		}
+	}
+}
+
+// cards returns every card in the deck, wherever it is.
+func (d *Deck) cards() []*Card {
+	all := make([]*Card, 0, len(d.DrawPile)+len(d.DiscardPile)+len(d.InPlay))
+	all = append(all, d.DrawPile...)
+	all = append(all, d.DiscardPile...)
+	return append(all, d.InPlay...)
+}
+
+// CardCount is how many copies of a machine are in a pile.
+type CardCount struct {
+	Machine sim.MachineInterface
+	Count   int
+}
+
+// countCards groups a pile by machine name, sorted by name, so a pile can be
+// shown without giving away its order.
+func countCards(pile []*Card) []CardCount {
+	index := make(map[string]int)
+	var counts []CardCount
+	for _, card := range pile {
+		name := sim.SpecOf(card.Machine).Name
+		if i, ok := index[name]; ok {
+			counts[i].Count++
+			continue
+		}
+		index[name] = len(counts)
+		counts = append(counts, CardCount{Machine: card.Machine, Count: 1})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return sim.SpecOf(counts[i].Machine).Name < sim.SpecOf(counts[j].Machine).Name
+	})
+	return counts
+}
+
+// shuffleCards shuffles a pile in place.
+func shuffleCards(rng *rand.Rand, pile []*Card) {
+	rng.Shuffle(len(pile), func(i, j int) {
+		pile[i], pile[j] = pile[j], pile[i]
+	})
+}
+
+// dealMachines draws up to n cards from the deck and returns a new machine for
+// each of them.
+func dealMachines(deck *Deck, rng *rand.Rand, n int, runsLeft int) []*MachineState {
+	result := make([]*MachineState, 0, n)
+	for i := 0; i < n; i++ {
+		card := deck.draw(rng)
+		if card == nil {
+			break
+		}
+		result = append(result, newMachineState(card, runsLeft))
+	}
+	return result
+}
+
+// newMachineState returns a new, unplaced machine for a card. Each machine is
+// its own instance rather than the card's.
+func newMachineState(card *Card, runsLeft int) *MachineState {
+	return &MachineState{
+		MachineState: sim.MachineState{Machine: card.Machine.New(), Orientation: sim.OrientationEast},
+		Card:         card,
+		RunAdded:     runsLeft,
+	}
+}
+
+// dealStartingDeck gives a new game its starting deck, with each mod's
+// default count of copies added, and deals the first hand from it. It also
+// builds the starting object deck and draws the first run's objects.
+func (s *GameState) dealStartingDeck(mods []*sim.Mod) {
+	machines, err := startingDeck(startingDeckName)
+	if err != nil {
+		panic(err)
+	}
+	for _, mod := range mods {
+		for i := 0; i < mod.Spec.DefaultCount; i++ {
+			machines = append(machines, mod.Spec.Machine)
+		}
+	}
+	s.deck = newDeck(s.rng, machines)
+	s.inventorySize = startingHandSize
+	s.inventory = dealMachines(s.deck, s.rng, startingHandSize, startingRunsLeft)
+	s.inventorySelected = make([]bool, len(s.inventory))
+	objects, err := startingObjects(startingDeckName)
+	if err != nil {
+		panic(err)
+	}
+	s.objectDeck = newObjectDeck(s.rng, objects)
+}
+
+// discardBoard returns every machine on the grid and in the inventory to the
+// discard pile and clears both.
+func (s *GameState) discardBoard() {
+	for pos, ms := range s.machines {
+		if ms != nil && s.isAnchor(pos) {
+			s.deck.discard(ms.Card)
+		}
+	}
+	for _, ms := range s.inventory {
+		s.deck.discard(ms.Card)
+	}
+	s.machines = make([]*MachineState, gridCols*gridRows)
+	s.inventory = nil
+	s.inventorySelected = nil
+}
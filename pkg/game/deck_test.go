@@ -0,0 +1,66 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github/brensch/game/pkg/sim"
+)
+
+// newPlacingState returns a game state with one machine dealt into the
+// inventory from a single-card deck.
+func newPlacingState(t *testing.T) (*GameState, *MachineState) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	s := &GameState{
+		rng:      rng,
+		machines: make([]*MachineState, gridCols*gridRows),
+		deck:     newDeck(rng, []sim.MachineInterface{&sim.Conveyor{}}),
+		runsLeft: startingRunsLeft,
+	}
+	s.inventory = dealMachines(s.deck, rng, 1, s.runsLeft)
+	s.inventorySelected = make([]bool, len(s.inventory))
+	if len(s.inventory) != 1 {
+		t.Fatalf("Expected 1 machine dealt, got %d", len(s.inventory))
+	}
+	ms := s.inventory[0]
+	if !s.placeFromInventory(ms, gridCols+1) {
+		t.Fatal("Expected the machine to be placed")
+	}
+	return s, ms
+}
+
+func TestPlaceFromInventory(t *testing.T) {
+	s, ms := newPlacingState(t)
+	if len(s.inventory) != 0 || len(s.inventorySelected) != 0 {
+		t.Errorf("Expected the inventory to be empty, got %d machines", len(s.inventory))
+	}
+	if s.machines[gridCols+1] != ms || !ms.IsPlaced {
+		t.Error("Expected the inventory machine itself on the grid")
+	}
+	if ms.Card == nil {
+		t.Error("Expected the placed machine to keep its card")
+	}
+}
+
+func TestSellPlacedMachineDiscardsCard(t *testing.T) {
+	s, ms := newPlacingState(t)
+	s.sellMachine(ms)
+	if len(s.deck.InPlay) != 0 {
+		t.Errorf("Expected no cards in play, got %d", len(s.deck.InPlay))
+	}
+	if len(s.deck.DiscardPile) != 1 || s.deck.DiscardPile[0] != ms.Card {
+		t.Errorf("Expected the machine's card on the discard pile, got %d cards", len(s.deck.DiscardPile))
+	}
+}
+
+func TestDiscardBoardDiscardsPlacedCard(t *testing.T) {
+	s, ms := newPlacingState(t)
+	s.discardBoard()
+	if len(s.deck.InPlay) != 0 {
+		t.Errorf("Expected no cards in play, got %d", len(s.deck.InPlay))
+	}
+	if len(s.deck.DiscardPile) != 1 || s.deck.DiscardPile[0] != ms.Card {
+		t.Errorf("Expected the machine's card on the discard pile, got %d cards", len(s.deck.DiscardPile))
+	}
+}
@@ -1,39 +1,22 @@
 package game
 
 import (
+	"errors"
+	"fmt"
+	"image"
 	"image/color"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 
+	"github/brensch/game/pkg/sim"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-func getMachineName(mt MachineType) string {
-	switch mt {
-	case MachineConveyor:
-		return "Conveyor"
-	case MachineProcessor:
-		return "Processor"
-	case MachineMiner:
-		return "Miner"
-	case MachineGeneralConsumer:
-		return "General Consumer"
-	case MachineSplitter:
-		return "Splitter"
-	case MachineAmplifier:
-		return "Amplifier"
-	case MachineCombiner:
-		return "Combiner"
-	case MachineBooster:
-		return "Booster"
-	case MachineCatalyst:
-		return "Catalyst"
-	default:
-		return "Unknown"
-	}
-}
-
 func wrapText(text string, maxLen int) []string {
 	// First split by newlines
 	paragraphs := strings.Split(text, "\n")
@@ -69,6 +52,22 @@ func wrapText(text string, maxLen int) []string {
 	return lines
 }
 
+// scoreDigits is the most digits a score is written out in full with. Longer
+// scores are shown in scientific notation, like 1.23e45.
+const scoreDigits = 5
+
+// formatScore writes a score out in full, or in scientific notation if it is
+// too long to fit in a score box.
+func formatScore(score *big.Int) string {
+	s := score.String()
+	if len(strings.TrimPrefix(s, "-")) <= scoreDigits {
+		return s
+	}
+	mantissa, exponent, _ := strings.Cut(new(big.Float).SetInt(score).Text('e', 2), "e")
+	power, _ := strconv.Atoi(exponent)
+	return mantissa + "e" + strconv.Itoa(power)
+}
+
 func (g *Game) drawScanlines(screen *ebiten.Image) {
 	bounds := screen.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
@@ -110,7 +109,7 @@ func (g *Game) drawFactoryFloor(screen *ebiten.Image) {
 	}
 }
 
-func (g *Game) drawArrow(screen *ebiten.Image, x, y float32, orientation Orientation) {
+func (g *Game) drawArrow(screen *ebiten.Image, x, y float32, orientation sim.Orientation) {
 	arrowColor := color.RGBA{R: 255, G: 255, B: 255, A: 255}
 	arrowSize := float32(g.cellSize / 6)
 	centerX := x + float32(g.cellSize)/2
@@ -126,25 +125,171 @@ func (g *Game) drawArrow(screen *ebiten.Image, x, y float32, orientation Orienta
 	topY := shaftY - arrowSize
 	bottomY := shaftY + arrowSize
 	switch orientation {
-	case OrientationNorth:
+	case sim.OrientationNorth:
 		vector.StrokeLine(screen, centerX, shaftEndY, centerX, shaftStartY, 1, arrowColor, false)
 		vector.StrokeLine(screen, leftX, arrowY, centerX, shaftStartY, 1, arrowColor, false)
 		vector.StrokeLine(screen, rightX, arrowY, centerX, shaftStartY, 1, arrowColor, false)
-	case OrientationEast:
+	case sim.OrientationEast:
 		vector.StrokeLine(screen, shaftLeft, shaftY, shaftRight, shaftY, 1, arrowColor, false)
 		vector.StrokeLine(screen, arrowX, topY, shaftRight, shaftY, 1, arrowColor, false)
 		vector.StrokeLine(screen, arrowX, bottomY, shaftRight, shaftY, 1, arrowColor, false)
-	case OrientationSouth:
+	case sim.OrientationSouth:
 		vector.StrokeLine(screen, centerX, shaftStartY, centerX, shaftEndY, 1, arrowColor, false)
 		vector.StrokeLine(screen, leftX, y+float32(g.cellSize)-2*arrowSize, centerX, shaftEndY, 1, arrowColor, false)
 		vector.StrokeLine(screen, rightX, y+float32(g.cellSize)-2*arrowSize, centerX, shaftEndY, 1, arrowColor, false)
-	case OrientationWest:
+	case sim.OrientationWest:
 		vector.StrokeLine(screen, shaftRight, shaftY, shaftLeft, shaftY, 1, arrowColor, false)
 		vector.StrokeLine(screen, x+2*arrowSize, topY, shaftLeft, shaftY, 1, arrowColor, false)
 		vector.StrokeLine(screen, x+2*arrowSize, bottomY, shaftLeft, shaftY, 1, arrowColor, false)
 	}
 }
 
+// machineSize returns the width and height in pixels of a machine's tile,
+// which spans every cell of its footprint.
+func (g *Game) machineSize(machine sim.MachineInterface, orientation sim.Orientation) (int, int) {
+	cols, rows := sim.Footprint(machine, orientation)
+	return cols*g.cellSize + (cols-1)*g.gridMargin, rows*g.cellSize + (rows-1)*g.gridMargin
+}
+
+// drawMachineTile draws a machine as one tile with its top-left corner at x,
+// y, covering its whole footprint.
+func (g *Game) drawMachineTile(screen *ebiten.Image, x, y int, machine sim.MachineInterface, orientation sim.Orientation) {
+	w, h := g.machineSize(machine, orientation)
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), sim.SpecOf(machine).Color, false)
+	g.drawPorts(screen, float32(x), float32(y), float32(w), float32(h), machine, orientation)
+}
+
+// drawObjectShape draws an object of the given colour and shape, size pixels
+// across, centred on x, y.
+func drawObjectShape(screen *ebiten.Image, x, y, size float32, c color.RGBA, shape sim.Shape) {
+	half := size / 2
+	switch shape {
+	case sim.ShapeCircle:
+		vector.DrawFilledCircle(screen, x, y, half, c, false)
+	case sim.ShapeDiamond:
+		fillPolygon(screen, c, x, y-half, x+half, y, x, y+half, x-half, y)
+	case sim.ShapeTriangle:
+		fillPolygon(screen, c, x, y-half, x+half, y+half, x-half, y+half)
+	default:
+		vector.DrawFilledRect(screen, x-half, y-half, size, size, c, false)
+	}
+}
+
+var (
+	whiteImage = ebiten.NewImage(3, 3)
+	// whitePixel is the middle pixel of whiteImage, used as the source for
+	// filled polygons.
+	whitePixel = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+)
+
+func init() {
+	whiteImage.Fill(color.White)
+}
+
+// fillPolygon fills the polygon through the given x, y pairs.
+func fillPolygon(screen *ebiten.Image, c color.RGBA, points ...float32) {
+	var path vector.Path
+	path.MoveTo(points[0], points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		path.LineTo(points[i], points[i+1])
+	}
+	path.Close()
+	vertices, indices := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	for i := range vertices {
+		vertices[i].ColorR = float32(c.R) / 255
+		vertices[i].ColorG = float32(c.G) / 255
+		vertices[i].ColorB = float32(c.B) / 255
+		vertices[i].ColorA = float32(c.A) / 255
+		vertices[i].SrcX, vertices[i].SrcY = 1, 1
+	}
+	screen.DrawTriangles(vertices, indices, whitePixel, &ebiten.DrawTrianglesOptions{})
+}
+
+// drawObject draws an object of the given colour, shape and edition, size
+// pixels across, centred on x, y. frame drives the editions that shimmer.
+func drawObject(screen *ebiten.Image, x, y, size float32, c color.RGBA, shape sim.Shape, edition sim.Edition, frame int) {
+	if edition == sim.EditionNegative {
+		c = color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: c.A}
+	}
+	drawObjectShape(screen, x, y, size, c, shape)
+	half := size / 2
+	switch edition {
+	case sim.EditionFoil:
+		// A silver rim with a glint across the object
+		silver := color.RGBA{R: 220, G: 220, B: 235, A: 255}
+		vector.StrokeRect(screen, x-half-2, y-half-2, size+4, size+4, 1, silver, false)
+		vector.StrokeLine(screen, x-half, y+half/2, x+half/2, y-half, 2, color.RGBA{R: 255, G: 255, B: 255, A: 200}, false)
+	case sim.EditionHolographic:
+		// A rim that shifts through the colours
+		vector.StrokeCircle(screen, x, y, half+4, 2, hueColor(float64(frame)*4), false)
+	case sim.EditionPolychrome:
+		// Four dots of different colours circling the object
+		for i := 0; i < 4; i++ {
+			angle := float64(frame)*0.1 + float64(i)*math.Pi/2
+			dx := float32(math.Cos(angle)) * (half + 5)
+			dy := float32(math.Sin(angle)) * (half + 5)
+			vector.DrawFilledCircle(screen, x+dx, y+dy, 2, hueColor(float64(i)*90), false)
+		}
+	case sim.EditionNegative:
+		vector.StrokeRect(screen, x-half-2, y-half-2, size+4, size+4, 1, color.RGBA{R: 20, G: 20, B: 20, A: 255}, false)
+	}
+}
+
+// hueColor returns the fully saturated colour at a hue in degrees.
+func hueColor(hue float64) color.RGBA {
+	h := math.Mod(hue, 360) / 60
+	x := uint8(255 * (1 - math.Abs(math.Mod(h, 2)-1)))
+	switch int(h) {
+	case 0:
+		return color.RGBA{R: 255, G: x, A: 255}
+	case 1:
+		return color.RGBA{R: x, G: 255, A: 255}
+	case 2:
+		return color.RGBA{G: 255, B: x, A: 255}
+	case 3:
+		return color.RGBA{G: x, B: 255, A: 255}
+	case 4:
+		return color.RGBA{R: x, B: 255, A: 255}
+	default:
+		return color.RGBA{R: 255, B: x, A: 255}
+	}
+}
+
+// drawPorts draws an arrow out of each of a machine's output sides and a
+// notch on the edge of each of its input sides, for a tile w by h pixels.
+func (g *Game) drawPorts(screen *ebiten.Image, x, y, w, h float32, machine sim.MachineInterface, orientation sim.Orientation) {
+	notchColor := color.RGBA{R: 30, G: 30, B: 30, A: 255}
+	notchW, notchH := w/3, h/3
+	for _, side := range machine.GetInputs() {
+		switch side.Facing(orientation) {
+		case sim.OrientationNorth:
+			vector.StrokeLine(screen, x+notchW, y+1, x+2*notchW, y+1, 3, notchColor, false)
+		case sim.OrientationEast:
+			vector.StrokeLine(screen, x+w-1, y+notchH, x+w-1, y+2*notchH, 3, notchColor, false)
+		case sim.OrientationSouth:
+			vector.StrokeLine(screen, x+notchW, y+h-1, x+2*notchW, y+h-1, 3, notchColor, false)
+		case sim.OrientationWest:
+			vector.StrokeLine(screen, x+1, y+notchH, x+1, y+2*notchH, 3, notchColor, false)
+		}
+	}
+	// Arrows are drawn one cell in size, in the middle of the tile.
+	size := float32(g.cellSize)
+	for _, side := range machine.GetOutputs() {
+		g.drawArrow(screen, x+(w-size)/2, y+(h-size)/2, side.Facing(orientation))
+	}
+}
+
+// drawScreenButton draws a labelled button that a screen hit-tests itself,
+// rather than one registered in initButtons.
+func (g *Game) drawScreenButton(screen *ebiten.Image, r screenRect, label string, c color.RGBA) {
+	vector.DrawFilledRect(screen, float32(r.x), float32(r.y), float32(r.w), float32(r.h), c, false)
+	width, _ := text.Measure(label, g.font, 0)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(r.x+(r.w-int(width))/2), float64(r.y+r.h/2-8))
+	op.ColorScale.ScaleWithColor(color.Black)
+	text.Draw(screen, label, g.font, op)
+}
+
 func (g *Game) drawRotateArrow(screen *ebiten.Image, x, y, width, height int, left bool) {
 	arrowColor := color.RGBA{R: 255, G: 255, B: 255, A: 255}
 	margin := float32(4)
@@ -258,8 +403,49 @@ func (g *Game) drawRotateArrow(screen *ebiten.Image, x, y, width, height int, le
 // 	}
 // }
 
+// drawRunError outlines the cells involved in the last run's error and
+// explains what went wrong above the grid. A run that stopped early is shown
+// ahead of a mod script that failed during it.
+func (g *Game) drawRunError(screen *ebiten.Image) {
+	var runErr *sim.RunError
+	var scriptErr *sim.ScriptError
+	var cells []int
+	var message string
+	switch {
+	case errors.As(g.state.runError, &runErr):
+		cells = runErr.Cells
+		message = fmt.Sprintf("Run stopped at tick %d", runErr.Tick)
+		switch {
+		case errors.Is(runErr, sim.ErrInfiniteLoop):
+			message = fmt.Sprintf("Infinite loop at tick %d", runErr.Tick)
+		case errors.Is(runErr, sim.ErrObjectExplosion):
+			message = fmt.Sprintf("Too many objects at tick %d", runErr.Tick)
+		}
+	case errors.As(g.state.runError, &scriptErr):
+		cells = []int{scriptErr.Position}
+		message = fmt.Sprintf("Script failed at tick %d: %v", scriptErr.Tick, scriptErr.Err)
+	default:
+		return
+	}
+	for _, pos := range cells {
+		col := pos % gridCols
+		row := pos / gridCols
+		if row < 1 || row > displayRows || col < 1 || col > displayCols {
+			continue
+		}
+		x := g.gridStartX + (col-1)*(g.cellSize+g.gridMargin)
+		y := g.gridStartY + (row-1)*(g.cellSize+g.gridMargin)
+		vector.StrokeRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), 3, color.RGBA{R: 255, G: 0, B: 0, A: 255}, false)
+	}
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(g.gridStartX), float64(g.gridStartY-20))
+	op.ColorScale.ScaleWithColor(color.RGBA{R: 255, G: 80, B: 80, A: 255})
+	text.Draw(screen, message, g.font, op)
+}
+
 func (g *Game) drawTooltip(screen *ebiten.Image) {
-	var tooltipMachine MachineInterface
+	var tooltipMachine sim.MachineInterface
 	var tooltipX, tooltipY int
 
 	// Check for long clicked machine
@@ -293,21 +479,14 @@ func (g *Game) drawTooltip(screen *ebiten.Image) {
 	}
 
 	if tooltipMachine != nil {
-		name := tooltipMachine.GetName()
-		description := tooltipMachine.GetDescription()
-		roles := tooltipMachine.GetRoles()
-		lines := wrapText(description, 40)
+		spec := sim.SpecOf(tooltipMachine)
+		name := spec.Name
+		lines := wrapText(spec.Description, 40)
 
 		// Build roles string
 		var rolesStr string
-		if len(roles) > 0 {
-			rolesStr = "Roles: "
-			for i, role := range roles {
-				if i > 0 {
-					rolesStr += ", "
-				}
-				rolesStr += getMachineRoleName(role)
-			}
+		if len(spec.Roles) > 0 {
+			rolesStr = "Roles: " + strings.Join(spec.RoleNames(), ", ")
 		}
 
 		// Calculate height
@@ -0,0 +1,69 @@
+package game
+
+import "math/big"
+
+const (
+	interestStep = 5 // $1 of interest for every $5 held at the end of a round
+	interestCap  = 5 // Most interest paid in one round
+	bonusSteps   = 4 // $1 bonus for every quarter of the target beaten by
+	bonusCap     = 5 // Most bonus paid in one round
+)
+
+// RoundEarnings itemizes the money earned over a round.
+type RoundEarnings struct {
+	Deliveries int // Paid by consumers for objects delivered during runs
+	Reward     int // Flat reward for clearing the round
+	Interest   int // Interest on money held at the end of the round
+	Bonus      int // Bonus for beating the target score by a margin
+}
+
+// Total returns all the money earned over the round.
+func (e RoundEarnings) Total() int {
+	return e.Deliveries + e.Reward + e.Interest + e.Bonus
+}
+
+// roundReward returns the flat reward for clearing a round.
+func roundReward(round int) int {
+	return (round + 1) * 10
+}
+
+// roundTarget returns the score the player's total must reach by the end of a
+// round: ten times the round squared.
+func roundTarget(round int) *big.Int {
+	return big.NewInt(int64(round * round * 10))
+}
+
+// interestOn returns the interest earned on money held at the end of a round.
+func interestOn(money int) int {
+	interest := money / interestStep
+	if interest > interestCap {
+		interest = interestCap
+	}
+	if interest < 0 {
+		interest = 0
+	}
+	return interest
+}
+
+// targetBonus returns the bonus for beating target by a margin: $1 for every
+// full quarter of the target the score went over by.
+func targetBonus(score, target *big.Int) int {
+	if target.Sign() <= 0 || score.Cmp(target) <= 0 {
+		return 0
+	}
+	bonus := new(big.Int).Sub(score, target)
+	bonus.Mul(bonus, big.NewInt(bonusSteps)).Quo(bonus, target)
+	if bonus.Cmp(big.NewInt(bonusCap)) > 0 {
+		return bonusCap
+	}
+	return int(bonus.Int64())
+}
+
+// settleRound pays out the end of a cleared round. Interest is worked out on
+// the money held before the reward and bonus are added.
+func (s *GameState) settleRound() {
+	s.earnings.Interest = interestOn(s.money)
+	s.earnings.Reward = roundReward(s.round)
+	s.earnings.Bonus = targetBonus(s.totalScore, s.targetScore)
+	s.money += s.earnings.Interest + s.earnings.Reward + s.earnings.Bonus
+}
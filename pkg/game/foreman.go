@@ -0,0 +1,97 @@
+package game
+
+import (
+	"github/brensch/game/pkg/sim"
+)
+
+const maxForemen = 5 // Foreman cards that fit in the foreman panel
+
+// ForemanCard is a foreman the player holds.
+type ForemanCard struct {
+	Spec    *sim.ForemanSpec
+	Foreman sim.Foreman
+}
+
+// ForemanOffer is a foreman card for sale in the shop.
+type ForemanOffer struct {
+	Spec  *sim.ForemanSpec
+	Price int
+	Sold  bool
+}
+
+// foremanPrice returns the shop price of a foreman card.
+func foremanPrice(spec *sim.ForemanSpec) int {
+	if spec.Cost > 0 {
+		return spec.Cost
+	}
+	return defaultPrice
+}
+
+// foremanSellValue returns what a foreman card sells for: half its price, and
+// never less than one.
+func foremanSellValue(card *ForemanCard) int {
+	value := foremanPrice(card.Spec) / 2
+	if value < 1 {
+		value = 1
+	}
+	return value
+}
+
+// simForemen returns the foremen the player holds, in order, for a run.
+func (s *GameState) simForemen() []sim.Foreman {
+	foremen := make([]sim.Foreman, len(s.foremen))
+	for i, card := range s.foremen {
+		foremen[i] = card.Foreman
+	}
+	return foremen
+}
+
+// foremanUnavailable returns why a foreman offer cannot be bought right now,
+// or an empty string if it can.
+func (s *GameState) foremanUnavailable(offer *ForemanOffer) string {
+	switch {
+	case offer.Sold:
+		return "Sold out"
+	case offer.Price > s.money:
+		return "Too expensive"
+	case len(s.foremen) >= maxForemen:
+		return "Foremen full"
+	default:
+		return ""
+	}
+}
+
+// buyForeman pays for a foreman offer and adds the card to the end of the
+// foreman panel. It reports false, changing nothing, if the offer is
+// unavailable.
+func (s *GameState) buyForeman(offer *ForemanOffer) bool {
+	if s.foremanUnavailable(offer) != "" {
+		return false
+	}
+	s.money -= offer.Price
+	offer.Sold = true
+	s.foremen = append(s.foremen, &ForemanCard{Spec: offer.Spec, Foreman: offer.Spec.New()})
+	return true
+}
+
+// sellForeman removes the foreman card at i and credits its sell value.
+func (s *GameState) sellForeman(i int) {
+	if i < 0 || i >= len(s.foremen) {
+		return
+	}
+	s.money += foremanSellValue(s.foremen[i])
+	s.foremen = append(s.foremen[:i:i], s.foremen[i+1:]...)
+	s.selectedForeman = -1
+}
+
+// moveForeman moves the foreman card at from to position to, shifting the
+// cards between along. Foremen are evaluated in order, so this changes what
+// they do to a run.
+func (s *GameState) moveForeman(from, to int) {
+	if from < 0 || from >= len(s.foremen) || to < 0 || to >= len(s.foremen) {
+		return
+	}
+	card := s.foremen[from]
+	s.foremen = append(s.foremen[:from:from], s.foremen[from+1:]...)
+	s.foremen = append(s.foremen[:to:to], append([]*ForemanCard{card}, s.foremen[to:]...)...)
+}
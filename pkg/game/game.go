@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"math/big"
 	"math/rand"
 	"sort"
+	"strings"
+
+	"github/brensch/game/pkg/sim"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -22,82 +26,28 @@ const (
 	minGap          = 10
 	buttonWidth     = 100
 
-	gridCols    = 9
-	gridRows    = 9
+	gridCols    = sim.GridCols
+	gridRows    = sim.GridRows
 	displayCols = 7
 	displayRows = 7
 
 	longClickThreshold = 20 // Frames before a click becomes a long click
-)
-
-// ObjectType represents the different kinds of items that can move through the factory.
-type ObjectType int
-
-const (
-	ObjectRed ObjectType = iota
-	ObjectGreen
-	ObjectBlue
-)
-
-// Object represents an item moving through the factory.
-type Object struct {
-	GridPosition int
-	Type         ObjectType
-	Score        *Score
-	Effects      []EffectInterface
-}
-
-// MachineType represents the different kinds of machines.
-type MachineType int
 
-const (
-	MachineConveyor MachineType = iota
-	MachineProcessor
-	MachineMiner
-	MachineGeneralConsumer
-	MachineSplitter
-	MachineAmplifier
-	MachineCombiner
-	MachineBooster
-	MachineCatalyst
-)
-
-// MachineRole represents the roles a machine can have.
-type MachineRole int
-
-const (
-	RoleProducer MachineRole = iota
-	RoleConsumer
-	RoleMover
-	RoleUpgrader
+	maxInventory = 7 // One row of the available panel
 )
 
-// getMachineRoleName returns the name of a machine role.
-func getMachineRoleName(role MachineRole) string {
-	switch role {
-	case RoleProducer:
-		return "Producer"
-	case RoleConsumer:
-		return "Consumer"
-	case RoleMover:
-		return "Mover"
-	case RoleUpgrader:
-		return "Upgrader"
-	default:
-		return "Unknown"
-	}
+// MachineState holds a machine on the grid or in the inventory, along with the
+// UI state needed to select and drag it around.
+type MachineState struct {
+	sim.MachineState
+	BeingDragged bool
+	IsPlaced     bool
+	RunAdded     int
+	Selected     bool
+	OriginalPos  int
+	Card         *Card
 }
 
-// Orientation represents the direction a machine is facing.
-type Orientation int
-
-const (
-	OrientationNorth Orientation = iota
-	OrientationEast
-	OrientationSouth
-	OrientationWest
-)
-
 // GamePhase represents the current state of the game (building or running).
 type GamePhase int
 
@@ -107,15 +57,36 @@ const (
 	PhaseRoundEnd
 	PhaseGameOver
 	PhaseInfo
+	PhaseShop
+	PhaseDeck
 )
 
-// Animation represents a moving object animation.
+// Animation represents a moving object animation. Color and Shape come from
+// the object's type. Buffed marks objects that a machine effect modified on
+// this step, ObjectID is the simulation ID of the object being moved and Fate
+// is what becomes of it where it lands.
 type Animation struct {
 	StartX, StartY float64
 	EndX, EndY     float64
 	Color          color.RGBA
+	Shape          sim.Shape
+	Edition        sim.Edition
 	Duration       float64
 	Elapsed        float64
+	Buffed         bool
+	ObjectID       int
+	Fate           sim.Fate
+	Retrigger      int
+}
+
+// screenRect is a rectangle on the screen.
+type screenRect struct {
+	x, y, w, h int
+}
+
+// contains reports whether a screen position is inside the rectangle.
+func (r screenRect) contains(x, y int) bool {
+	return x >= r.x && x <= r.x+r.w && y >= r.y && y <= r.y+r.h
 }
 
 func abs(x int) int {
@@ -130,29 +101,38 @@ type GameState struct {
 	phase              GamePhase
 	money              int
 	runsLeft           int
-	baseScore          int
-	multiplier         int
-	multMult           int
+	multiplier         *big.Int
 	machines           []*MachineState
 	inventory          []*MachineState
-	catalogue          []MachineInterface
+	deck               *Deck
+	objectDeck         *ObjectDeck
+	rewards            []ObjectReward
+	foremen            []*ForemanCard
+	selectedForeman    int
 	inventorySize      int
-	restocksLeft       int
 	inventorySelected  []bool
-	objects            []*Object
 	round              int
 	animations         []*Animation
+	objectPositions    map[int][2]float64
+	leftovers          []*Animation
 	animationTick      int
 	animationSpeed     float64
 	buttons            map[string]*Button
-	allChanges         [][]*Change
-	roundScore         int
-	totalScore         int
-	targetScore        int
+	allChanges         [][]*sim.Change
+	runResults         <-chan runResult
+	runTotal           sim.RunTotal
+	runError           error
+	roundScore         *big.Int
+	totalScore         *big.Int
+	targetScore        *big.Int
 	gameOver           bool
 	endRunDelay        int
 	previousPhase      GamePhase
 	longClickedMachine *MachineState
+	shop               *Shop
+	earnings           RoundEarnings
+	seed               int64
+	rng                *rand.Rand
 }
 
 // Game implements ebiten.Game.
@@ -169,6 +149,10 @@ type Game struct {
 	font          text.Face
 	lastInput     InputState
 	frameCount    int
+	mods          []*sim.Mod
+	// discovered holds the keys of the recipes crafted so far this session,
+	// kept across restarts so the recipe book fills up over time.
+	discovered map[string]bool
 }
 
 func (g *Game) getSelectedMachine() *MachineState {
@@ -214,41 +198,33 @@ func (g *Game) getDraggingMachine() *MachineState {
 	return nil
 }
 
-func dealMachines(catalogue []MachineInterface, n int, runsLeft int) []*MachineState {
-	result := make([]*MachineState, 0, n)
-	for i := 0; i < n && len(catalogue) > 0; i++ {
-		idx := rand.Intn(len(catalogue))
-		machine := catalogue[idx]
-		result = append(result, &MachineState{Machine: machine, Orientation: OrientationEast, BeingDragged: false, IsPlaced: false, RunAdded: runsLeft})
-		// Remove from catalogue
-		catalogue = append(catalogue[:idx], catalogue[idx+1:]...)
-	}
-	return result
-}
-
-// NewGame creates a new Game instance.
-func NewGame(width, height int) *Game {
+// NewGame creates a new Game instance. Every random decision in the game is
+// drawn from seed, so the same seed and the same actions give the same game.
+// Machines from mods join the starting deck alongside the built-in ones.
+func NewGame(width, height int, seed int64, mods []*sim.Mod) *Game {
 	state := &GameState{
-		phase:          PhaseBuild,
-		money:          10,
-		runsLeft:       6,
-		machines:       make([]*MachineState, gridCols*gridRows),
-		round:          1,
-		animations:     []*Animation{},
-		animationTick:  0,
-		animationSpeed: 1.0,
-		buttons:        make(map[string]*Button),
-		multiplier:     1,
-		multMult:       1,
-		roundScore:     0,
-		totalScore:     0,
-		targetScore:    10, // round * 10
-		gameOver:       false,
-		endRunDelay:    0,
-		previousPhase:  PhaseBuild,
-	}
-
-	g := &Game{state: state}
+		phase:           PhaseBuild,
+		money:           10,
+		runsLeft:        6,
+		machines:        make([]*MachineState, gridCols*gridRows),
+		round:           1,
+		animations:      []*Animation{},
+		animationTick:   0,
+		animationSpeed:  1.0,
+		buttons:         make(map[string]*Button),
+		multiplier:      big.NewInt(1),
+		roundScore:      big.NewInt(0),
+		totalScore:      big.NewInt(0),
+		targetScore:     roundTarget(1),
+		gameOver:        false,
+		endRunDelay:     0,
+		previousPhase:   PhaseBuild,
+		selectedForeman: -1,
+		seed:            seed,
+		rng:             rand.New(rand.NewSource(seed)),
+	}
+
+	g := &Game{state: state, mods: mods, discovered: make(map[string]bool)}
 	g.width = width
 	g.height = height
 	source, err := text.NewGoTextFaceSource(bytes.NewReader(gomono.TTF))
@@ -261,27 +237,7 @@ func NewGame(width, height int) *Game {
 	// Initialize buttons
 	g.initButtons()
 
-	state.catalogue = []MachineInterface{
-		&Conveyor{},
-		&Conveyor{},
-		&Conveyor{},
-		&Conveyor{},
-		&Conveyor{},
-		&Processor{},
-		&Processor{},
-		&Miner{},
-		&Miner{},
-		&Splitter{},
-		&GeneralConsumer{},
-		&Amplifier{},
-		&Combiner{},
-		&Booster{},
-		&Catalyst{},
-	}
-	state.inventorySize = 5
-	state.restocksLeft = 3
-	state.inventory = dealMachines(state.catalogue, 5, 6)
-	state.inventorySelected = make([]bool, len(state.inventory))
+	state.dealStartingDeck(mods)
 
 	return g
 }
@@ -295,6 +251,8 @@ func (g *Game) initButtons() {
 	restartBtn.States[PhaseBuild] = &ButtonState{Text: "Restart", Color: color.RGBA{R: 200, G: 100, B: 100, A: 255}, Disabled: false, Visible: true}
 	restartBtn.States[PhaseRun] = &ButtonState{Text: "Restart", Color: color.RGBA{R: 200, G: 100, B: 100, A: 255}, Disabled: false, Visible: true}
 	restartBtn.States[PhaseRoundEnd] = &ButtonState{Text: "Restart", Color: color.RGBA{R: 200, G: 100, B: 100, A: 255}, Disabled: false, Visible: true}
+	restartBtn.States[PhaseShop] = &ButtonState{Text: "Restart", Color: color.RGBA{R: 200, G: 100, B: 100, A: 255}, Disabled: false, Visible: true}
+	restartBtn.States[PhaseDeck] = &ButtonState{Text: "Restart", Color: color.RGBA{R: 200, G: 100, B: 100, A: 255}, Disabled: false, Visible: true}
 	restartBtn.Font = g.font
 	g.state.buttons["restart"] = restartBtn
 
@@ -350,24 +308,16 @@ func (g *Game) initButtons() {
 	infoBtn.Color = color.RGBA{R: 100, G: 100, B: 200, A: 255} // Blue
 	infoBtn.States[PhaseBuild] = &ButtonState{Text: "Info", Color: color.RGBA{R: 100, G: 100, B: 200, A: 255}, Disabled: false, Visible: true}
 	infoBtn.States[PhaseRoundEnd] = &ButtonState{Text: "Info", Color: color.RGBA{R: 100, G: 100, B: 200, A: 255}, Disabled: false, Visible: true}
+	infoBtn.States[PhaseShop] = &ButtonState{Text: "Info", Color: color.RGBA{R: 100, G: 100, B: 200, A: 255}, Disabled: false, Visible: true}
 	infoBtn.Font = g.font
 	g.state.buttons["info"] = infoBtn // Close info button
 	closeInfoBtn := &Button{}
-	closeInfoBtn.Init(g.screenWidth/2-50, g.height/2+50, 100, 30, "Close", handleCloseInfoClick)
+	closeInfoBtn.Init(g.screenWidth/2-50, g.height/2+200, 100, 30, "Close", handleCloseInfoClick)
 	closeInfoBtn.Color = color.RGBA{R: 100, G: 200, B: 100, A: 255} // Green
 	closeInfoBtn.States[PhaseInfo] = &ButtonState{Text: "Close", Color: color.RGBA{R: 100, G: 200, B: 100, A: 255}, Disabled: false, Visible: true}
 	closeInfoBtn.Font = g.font
 	g.state.buttons["close_info"] = closeInfoBtn
 
-	// Restock button
-	restockBtn := &Button{}
-	restockX := gridRightEdge - 2*g.cellSize - g.gridMargin - 80 - g.gridMargin - 80 - g.gridMargin
-	restockBtn.Init(restockX, g.availableY+g.cellSize+g.gridMargin, 80, 30, "Restock", handleRestockClick)
-	restockBtn.Color = color.RGBA{R: 200, G: 100, B: 200, A: 255} // Purple
-	restockBtn.States[PhaseBuild] = &ButtonState{Text: "Restock", Color: color.RGBA{R: 200, G: 100, B: 200, A: 255}, Disabled: false, Visible: false}
-	restockBtn.Font = g.font
-	g.state.buttons["restock"] = restockBtn
-
 	// Sell button
 	sellBtn := &Button{}
 	sellWidth := 2*buttonSize + gap
@@ -420,15 +370,7 @@ func (g *Game) repositionButtons() {
 	// Close info button
 	if closeInfoBtn, exists := g.state.buttons["close_info"]; exists {
 		closeInfoBtn.X = g.screenWidth/2 - 50
-		closeInfoBtn.Y = g.height/2 + 50
-	}
-
-	// Restock button
-	if restockBtn, exists := g.state.buttons["restock"]; exists {
-		gridRightEdge := g.gridStartX + displayCols*g.cellSize + (displayCols-1)*g.gridMargin
-		restockX := gridRightEdge - 2*g.cellSize - g.gridMargin - 80 - g.gridMargin - 80 - g.gridMargin
-		restockBtn.X = restockX
-		restockBtn.Y = g.availableY + g.cellSize + g.gridMargin
+		closeInfoBtn.Y = g.height/2 + 200
 	}
 
 	// Sell button is repositioned dynamically in phase_drag.go, skip here
@@ -464,13 +406,14 @@ func (g *Game) calculateLayout() {
 	g.gridMargin = int(float64(g.cellSize) * marginRatio)
 
 	gridHeight := displayRows*g.cellSize + (displayRows-1)*g.gridMargin
-	totalFixedHeight := gridHeight + g.availableHeight + g.bottomHeight + g.infoBarHeight
-	gap := (g.height - totalFixedHeight) / 4
+	totalFixedHeight := g.foremanHeight + gridHeight + g.availableHeight + g.bottomHeight + g.infoBarHeight
+	gap := (g.height - totalFixedHeight) / 5
 	if gap < minGap {
 		gap = minGap
 	}
 	g.topPanelY = 0
-	g.gridStartY = g.topPanelY + gap
+	g.foremanY = g.topPanelY + gap
+	g.gridStartY = g.foremanY + g.foremanHeight + gap
 	g.availableY = g.gridStartY + gridHeight + gap
 	g.bottomY = g.height - g.bottomHeight - g.infoBarHeight
 	g.screenWidth = g.width
@@ -501,6 +444,91 @@ func (g *Game) getPos(ms *MachineState) int {
 	return -1
 }
 
+// isAnchor reports whether pos holds a machine and is its top-left cell. A
+// machine covering several cells holds the same *MachineState in each of
+// them, and is drawn once, from its anchor.
+func (s *GameState) isAnchor(pos int) bool {
+	ms := s.machines[pos]
+	if ms == nil {
+		return false
+	}
+	if pos%gridCols > 0 && s.machines[pos-1] == ms {
+		return false
+	}
+	return pos < gridCols || s.machines[pos-gridCols] != ms
+}
+
+// canPlace reports whether ms fits on the factory floor with its top-left
+// cell at anchor, covering only empty cells or cells it already covers.
+func (s *GameState) canPlace(ms *MachineState, anchor int) bool {
+	cells := sim.CoveredCells(anchor, ms.Machine, ms.Orientation)
+	if cells == nil {
+		return false
+	}
+	for _, pos := range cells {
+		if !sim.IsFloor(pos) || (s.machines[pos] != nil && s.machines[pos] != ms) {
+			return false
+		}
+	}
+	return true
+}
+
+// placeMachine puts ms on the grid with its top-left cell at anchor, clearing
+// any cells it covered before. It reports false, changing nothing, if ms does
+// not fit there.
+func (s *GameState) placeMachine(ms *MachineState, anchor int) bool {
+	if !s.canPlace(ms, anchor) {
+		return false
+	}
+	s.removeMachine(ms)
+	for _, pos := range sim.CoveredCells(anchor, ms.Machine, ms.Orientation) {
+		s.machines[pos] = ms
+	}
+	return true
+}
+
+// placeFromInventory moves ms out of the inventory and onto the grid with its
+// top-left cell at anchor, marking it as placed this run. The machine keeps its
+// card, so selling or discarding it later returns the card to the discard
+// pile. It reports false, changing nothing, if ms does not fit there.
+func (s *GameState) placeFromInventory(ms *MachineState, anchor int) bool {
+	if !s.placeMachine(ms, anchor) {
+		return false
+	}
+	ms.IsPlaced = true
+	ms.RunAdded = s.runsLeft
+	for i, m := range s.inventory {
+		if m == ms {
+			s.inventory = append(s.inventory[:i], s.inventory[i+1:]...)
+			s.inventorySelected = append(s.inventorySelected[:i], s.inventorySelected[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// removeMachine clears every cell ms covers.
+func (s *GameState) removeMachine(ms *MachineState) {
+	for pos, m := range s.machines {
+		if m == ms {
+			s.machines[pos] = nil
+		}
+	}
+}
+
+// simMachines returns the placed machines as the simulation sees them, one
+// entry per grid cell with nil for empty cells. Cells covered by the same
+// machine share one *sim.MachineState.
+func (s *GameState) simMachines() []*sim.MachineState {
+	machines := make([]*sim.MachineState, len(s.machines))
+	for pos, ms := range s.machines {
+		if ms != nil {
+			machines[pos] = &ms.MachineState
+		}
+	}
+	return machines
+}
+
 // processButtons processes all button clicks using their individual handlers.
 func (g *Game) processButtons() {
 	for _, button := range g.state.buttons {
@@ -515,11 +543,17 @@ func (g *Game) Update() error {
 
 	switch g.state.phase {
 	case PhaseBuild:
-		g.handleDragAndDrop()
+		if !g.handleForemen() {
+			g.handleDragAndDrop()
+		}
 	case PhaseRun:
 		g.handleRunPhase()
+	case PhaseShop:
+		g.handleShop()
+	case PhaseDeck:
+		g.handleDeck()
 	case PhaseRoundEnd:
-		// Handle round end phase
+		g.handleRoundEnd()
 	}
 
 	// // Update button positions based on current state
@@ -540,6 +574,10 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.drawRunLayout(screen)
 	case PhaseRoundEnd:
 		g.drawRoundEndLayout(screen)
+	case PhaseShop:
+		g.drawShopLayout(screen)
+	case PhaseDeck:
+		g.drawDeckLayout(screen)
 	case PhaseGameOver:
 		// Draw a simple game over screen
 		g.drawRoundEndLayout(screen) // or something
@@ -550,6 +588,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			g.drawDragLayout(screen)
 		case PhaseRoundEnd:
 			g.drawRoundEndLayout(screen)
+		case PhaseShop:
+			g.drawShopLayout(screen)
 		}
 	}
 
@@ -583,7 +623,11 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		op2 := &text.DrawOptions{}
 		op2.GeoM.Translate(float64(popupX+20), float64(popupY+60))
 		op2.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, fmt.Sprintf("Final Score: %d", g.state.totalScore), g.font, op2)
+		text.Draw(screen, fmt.Sprintf("Final Score: %s", formatScore(g.state.totalScore)), g.font, op2)
+		op3 := &text.DrawOptions{}
+		op3.GeoM.Translate(float64(popupX+20), float64(popupY+90))
+		op3.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, fmt.Sprintf("Seed: %d", g.state.seed), g.font, op3)
 		g.state.buttons["popup_restart"].Render(screen, g.state)
 	}
 
@@ -601,8 +645,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		text.Draw(screen, "Machine Catalogue", g.font, op3)
 		yOffset := popupY + 60
 		uniqueMachines := make(map[string]bool)
-		for _, machine := range g.state.catalogue {
-			uniqueMachines[machine.GetName()] = true
+		for _, card := range g.state.deck.cards() {
+			uniqueMachines[sim.SpecOf(card.Machine).Name] = true
 		}
 		var names []string
 		for name := range uniqueMachines {
@@ -616,6 +660,43 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			text.Draw(screen, name, g.font, nameOp)
 			yOffset += 20
 		}
+		// List the mods loaded at startup
+		modsTitle := fmt.Sprintf("Mods (%d)", len(g.mods))
+		if len(g.mods) == 0 {
+			modsTitle = "No mods loaded"
+		}
+		yOffset += 10
+		modsOp := &text.DrawOptions{}
+		modsOp.GeoM.Translate(float64(popupX+20), float64(yOffset))
+		modsOp.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, modsTitle, g.font, modsOp)
+		yOffset += 20
+		for _, mod := range g.mods {
+			modOp := &text.DrawOptions{}
+			modOp.GeoM.Translate(float64(popupX+30), float64(yOffset))
+			modOp.ColorScale.ScaleWithColor(color.RGBA{R: 180, G: 180, B: 180, A: 255})
+			text.Draw(screen, fmt.Sprintf("%s (%s)", mod.Spec.Name, mod.File), g.font, modOp)
+			yOffset += 20
+		}
+		// List the recipes crafted so far, hiding the rest
+		recipes := sim.Recipes()
+		yOffset += 10
+		recipesOp := &text.DrawOptions{}
+		recipesOp.GeoM.Translate(float64(popupX+20), float64(yOffset))
+		recipesOp.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, fmt.Sprintf("Recipes (%d/%d)", len(g.discovered), len(recipes)), g.font, recipesOp)
+		yOffset += 20
+		for _, recipe := range recipes {
+			line := "???"
+			if g.discovered[recipe.Key] {
+				line = recipeLine(recipe)
+			}
+			recipeOp := &text.DrawOptions{}
+			recipeOp.GeoM.Translate(float64(popupX+30), float64(yOffset))
+			recipeOp.ColorScale.ScaleWithColor(color.RGBA{R: 180, G: 180, B: 180, A: 255})
+			text.Draw(screen, line, g.font, recipeOp)
+			yOffset += 20
+		}
 		g.state.buttons["close_info"].Render(screen, g.state)
 	}
 }
@@ -689,3 +770,28 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 
 	return outsideWidth, outsideHeight
 }
+
+// recipeLine describes a recipe for the recipe book, grouping repeated
+// inputs, e.g. "3 Green = Emerald (+5, +2 x2 mult)".
+func recipeLine(recipe *sim.Recipe) string {
+	var order []sim.ObjectType
+	counts := make(map[sim.ObjectType]int)
+	for _, input := range recipe.Inputs {
+		if counts[input] == 0 {
+			order = append(order, input)
+		}
+		counts[input]++
+	}
+	names := make([]string, len(order))
+	for i, input := range order {
+		names[i] = sim.ObjectTypeOf(input).Name
+		if counts[input] > 1 {
+			names[i] = fmt.Sprintf("%d %s", counts[input], names[i])
+		}
+	}
+	bonus := fmt.Sprintf("+%d, +%d", recipe.Bonus.Value, recipe.Bonus.MultAdd)
+	if recipe.Bonus.MultMult != 1 {
+		bonus += fmt.Sprintf(" x%d", recipe.Bonus.MultMult)
+	}
+	return fmt.Sprintf("%s = %s (%s mult)", strings.Join(names, " + "), sim.ObjectTypeOf(recipe.Output).Name, bonus)
+}
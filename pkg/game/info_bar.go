@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"image/color"
+	"math/big"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -25,19 +26,19 @@ func (g *Game) drawInfoBar(screen *ebiten.Image, y int) {
 	topRowHeight := rowHeight + 5
 	bottomRowHeight := rowHeight - 5
 
-	// Top row: Round | Runs Left | Money | Restocks Left
+	// Top row: Round | Runs Left | Money | Reroll cost
 	colors := []color.RGBA{
 		{R: 135, G: 206, B: 235, A: 255}, // Sky blue for Round
 		{R: 144, G: 238, B: 144, A: 255}, // Light green for Runs Left
 		{R: 255, G: 215, B: 0, A: 255},   // Gold for Money
-		{R: 255, G: 99, B: 71, A: 255},   // Tomato red for Restocks
+		{R: 255, G: 99, B: 71, A: 255},   // Tomato red for Reroll
 	}
-	labels := []string{"Round", "Runs Left", "Money", "Restocks"}
+	labels := []string{"Round", "Runs Left", "Money", "Reroll"}
 	values := []string{
 		fmt.Sprintf("%d", g.state.round),
 		fmt.Sprintf("%d", g.state.runsLeft),
 		fmt.Sprintf("$%d", g.state.money),
-		fmt.Sprintf("%d", g.state.restocksLeft),
+		fmt.Sprintf("$%d", g.state.rerollCost()),
 	}
 
 	source, err := text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
@@ -79,7 +80,7 @@ func (g *Game) drawInfoBar(screen *ebiten.Image, y int) {
 
 	// Bottom row: Full width progress bar
 	bottomY := y + topRowHeight
-	progressText := fmt.Sprintf("Score %d / Target %d", g.state.totalScore, g.state.targetScore)
+	progressText := fmt.Sprintf("Score %s / Target %s", formatScore(g.state.totalScore), formatScore(g.state.targetScore))
 	textWidth := len(progressText) * 6
 	textX := buttonSpace + (contentWidth-textWidth)/2
 	ebitenutil.DebugPrintAt(screen, progressText, textX, bottomY+5)
@@ -91,7 +92,7 @@ func (g *Game) drawInfoBar(screen *ebiten.Image, y int) {
 	barLeft := buttonSpace + barMargin
 	barRight := g.screenWidth - barMargin
 	barWidth := barRight - barLeft
-	progress := float64(g.state.totalScore) / float64(g.state.targetScore)
+	progress, _ := new(big.Rat).SetFrac(g.state.totalScore, g.state.targetScore).Float64()
 	if progress > 1.0 {
 		progress = 1.0
 	}
@@ -0,0 +1,58 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github/brensch/game/pkg/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// deckLayout returns where the back button sits on the deck screen.
+func (g *Game) deckLayout() (back screenRect) {
+	margin := 20
+	return screenRect{x: margin, y: g.height - g.infoBarHeight - 40 - margin, w: g.screenWidth - 2*margin, h: 40}
+}
+
+// drawDeckLayout lists what is left in the draw pile, grouped by machine so
+// the order of the pile stays hidden, along with the size of the other piles.
+func (g *Game) drawDeckLayout(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, float32(g.screenWidth), float32(g.height), color.RGBA{R: 50, G: 50, B: 50, A: 255}, false)
+	deck := g.state.deck
+
+	title := &text.DrawOptions{}
+	title.GeoM.Translate(20, float64(g.topPanelHeight/2-10))
+	title.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, fmt.Sprintf("Draw pile - %d cards", len(deck.DrawPile)), g.font, title)
+
+	y := g.topPanelHeight
+	rowH := 30
+	for _, count := range countCards(deck.DrawPile) {
+		vector.DrawFilledRect(screen, 20, float32(y), float32(rowH-6), float32(rowH-6), sim.SpecOf(count.Machine).Color, false)
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(20+float64(rowH), float64(y+2))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, fmt.Sprintf("%dx %s", count.Count, sim.SpecOf(count.Machine).Name), g.font, op)
+		y += rowH
+	}
+	if len(deck.DrawPile) == 0 {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(20, float64(y+2))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, "Empty - reshuffles on the next draw", g.font, op)
+		y += rowH
+	}
+
+	piles := &text.DrawOptions{}
+	piles.GeoM.Translate(20, float64(y+rowH/2))
+	piles.ColorScale.ScaleWithColor(color.RGBA{R: 180, G: 180, B: 180, A: 255})
+	text.Draw(screen, fmt.Sprintf("Discard pile %d, in play %d", len(deck.DiscardPile), len(deck.InPlay)), g.font, piles)
+
+	g.drawScreenButton(screen, g.deckLayout(), "Back", color.RGBA{R: 100, G: 200, B: 100, A: 255})
+
+	// Draw info bar at bottom
+	g.drawInfoBar(screen, g.height-g.topPanelHeight)
+}
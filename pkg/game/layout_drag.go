@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"image/color"
 
+	"github/brensch/game/pkg/sim"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -14,6 +16,7 @@ import (
 func (g *Game) drawDragLayout(screen *ebiten.Image) {
 	// Draw factory floor
 	g.drawFactoryFloor(screen)
+	g.drawForemen(screen)
 
 	// Draw available machines
 	for i, ms := range g.state.inventory {
@@ -22,7 +25,7 @@ func (g *Game) drawDragLayout(screen *ebiten.Image) {
 			col := i % 7
 			x := g.gridStartX + col*(g.cellSize+g.gridMargin)
 			y := g.availableY + row*(g.cellSize+g.gridMargin)
-			vector.DrawFilledRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), ms.Machine.GetColor(), false)
+			vector.DrawFilledRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), sim.SpecOf(ms.Machine).Color, false)
 			if g.state.inventorySelected[i] {
 				vector.StrokeRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), 3, color.RGBA{R: 255, G: 0, B: 0, A: 255}, false)
 			}
@@ -31,7 +34,7 @@ func (g *Game) drawDragLayout(screen *ebiten.Image) {
 
 	// Draw placed machines
 	for pos, ms := range g.state.machines {
-		if ms == nil || ms.Machine == nil || ms.BeingDragged {
+		if ms == nil || ms.Machine == nil || ms.BeingDragged || !g.state.isAnchor(pos) {
 			continue
 		}
 		col := pos % gridCols
@@ -41,26 +44,21 @@ func (g *Game) drawDragLayout(screen *ebiten.Image) {
 		}
 		x := g.gridStartX + (col-1)*(g.cellSize+g.gridMargin)
 		y := g.gridStartY + (row-1)*(g.cellSize+g.gridMargin)
-		vector.DrawFilledRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), ms.Machine.GetColor(), false)
-
-		g.drawArrow(screen, float32(x), float32(y), ms.Orientation)
+		g.drawMachineTile(screen, x, y, ms.Machine, ms.Orientation)
 		if ms.Selected {
-			vector.StrokeRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), 3, color.RGBA{R: 255, G: 255, B: 0, A: 255}, false)
+			w, h := g.machineSize(ms.Machine, ms.Orientation)
+			vector.StrokeRect(screen, float32(x), float32(y), float32(w), float32(h), 3, color.RGBA{R: 255, G: 255, B: 0, A: 255}, false)
 		}
 	}
 
-	// Draw the dragging machine on top
-	if dragging := g.getDraggingMachine(); dragging != nil {
-		cx, cy := g.lastInput.X, g.lastInput.Y
-		vector.DrawFilledRect(screen, float32(cx-g.cellSize/2), float32(cy-g.cellSize/2), float32(g.cellSize), float32(g.cellSize), dragging.Machine.GetColor(), false)
-	}
+	g.drawRunError(screen)
 
 	// Draw animations
 	for _, anim := range g.state.animations {
 		progress := anim.Elapsed / anim.Duration
 		x := anim.StartX + (anim.EndX-anim.StartX)*progress
 		y := anim.StartY + (anim.EndY-anim.StartY)*progress
-		vector.DrawFilledRect(screen, float32(x-2), float32(y-2), 4, 4, anim.Color, false)
+		drawObjectShape(screen, float32(x), float32(y), 4, anim.Color, anim.Shape)
 	}
 
 	// Draw bottom panel
@@ -72,7 +70,7 @@ func (g *Game) drawDragLayout(screen *ebiten.Image) {
 		panic(err)
 	}
 	faceLarge := &text.GoTextFace{Source: source, Size: 24}
-	smallBoxW := 60
+	smallBoxW := 90 // Room for a score like 1.23e45
 	smallBoxH := 40
 	gap := 10
 	xW := 10
@@ -104,20 +102,51 @@ func (g *Game) drawDragLayout(screen *ebiten.Image) {
 	vector.DrawFilledRect(screen, float32(multBoxX), float32(y), float32(smallBoxW), float32(smallBoxH), color.RGBA{R: 0, G: 0, B: 0, A: 255}, false)
 
 	// Base text
-	baseStr := fmt.Sprintf("%d", g.state.roundScore)
+	baseStr := formatScore(g.state.roundScore)
 	opBase := &text.DrawOptions{}
 	opBase.GeoM.Translate(float64(baseBoxX+10), float64(y+8))
 	opBase.ColorScale.ScaleWithColor(color.White)
 	text.Draw(screen, baseStr, faceLarge, opBase)
 
 	// Mult text
-	multStr := fmt.Sprintf("%d", g.state.multiplier)
+	multStr := formatScore(g.state.multiplier)
 	opMult := &text.DrawOptions{}
 	opMult.GeoM.Translate(float64(multBoxX+10), float64(y+8))
 	opMult.ColorScale.ScaleWithColor(color.White)
 	text.Draw(screen, multStr, faceLarge, opMult)
 
+	// Show the objects the next run will feed in, in the order they come out
+	g.drawNextHand(screen, multBoxX+smallBoxW+gap, y)
+
+	// Draw the sell zone while a placed machine is being dragged
+	if g.sellZoneActive() {
+		g.drawScreenButton(screen, g.sellZone(), fmt.Sprintf("Sell $%d", g.state.sellValue(g.getDraggingMachine())), color.RGBA{R: 200, G: 100, B: 100, A: 255})
+	}
+
+	// Draw the dragging machine on top
+	if dragging := g.getDraggingMachine(); dragging != nil {
+		// The cell under the cursor is where the machine's top-left cell lands.
+		cx, cy := g.lastInput.X, g.lastInput.Y
+		w, h := g.machineSize(dragging.Machine, dragging.Orientation)
+		vector.DrawFilledRect(screen, float32(cx-g.cellSize/2), float32(cy-g.cellSize/2), float32(w), float32(h), sim.SpecOf(dragging.Machine).Color, false)
+	}
+
 	// Draw info bar at bottom
 	g.drawInfoBar(screen, g.bottomY+g.bottomHeight)
 
 }
+
+// drawNextHand draws the objects drawn for the next run, left to right in the
+// order the miners will feed them in, starting at x, y.
+func (g *Game) drawNextHand(screen *ebiten.Image, x, y int) {
+	label := &text.DrawOptions{}
+	label.GeoM.Translate(float64(x), float64(y))
+	label.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, "Next run", g.font, label)
+	size := 12
+	for i, obj := range g.state.objectDeck.Hand {
+		objType := sim.ObjectTypeOf(obj.Type)
+		cx := x + size/2 + i*(size+10)
+		drawObject(screen, float32(cx), float32(y+30), float32(size), objType.Color, objType.Shape, obj.Edition, g.frameCount)
+	}
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// foremenLayout returns where each foreman slot sits in the foreman panel,
+// left to right in evaluation order.
+func (g *Game) foremenLayout() []screenRect {
+	margin := 10
+	slotW := (g.screenWidth - (maxForemen+1)*margin) / maxForemen
+	slots := make([]screenRect, maxForemen)
+	for i := range slots {
+		slots[i] = screenRect{x: margin + i*(slotW+margin), y: g.foremanY, w: slotW, h: g.foremanHeight}
+	}
+	return slots
+}
+
+// foremanSellButton returns the sell button along the bottom of a selected
+// foreman card.
+func foremanSellButton(slot screenRect) screenRect {
+	return screenRect{x: slot.x, y: slot.y + slot.h - 20, w: slot.w, h: 20}
+}
+
+// drawForemen draws the foreman panel: a card for each foreman held, in
+// evaluation order, and an empty outline for each free slot.
+func (g *Game) drawForemen(screen *ebiten.Image) {
+	for i, slot := range g.foremenLayout() {
+		if i >= len(g.state.foremen) {
+			vector.StrokeRect(screen, float32(slot.x), float32(slot.y), float32(slot.w), float32(slot.h), 1, color.RGBA{R: 90, G: 90, B: 90, A: 255}, false)
+			continue
+		}
+		card := g.state.foremen[i]
+		vector.DrawFilledRect(screen, float32(slot.x), float32(slot.y), float32(slot.w), float32(slot.h), color.RGBA{R: 70, G: 60, B: 40, A: 255}, false)
+		lines := wrapText(card.Spec.Name, slot.w/9)
+		lines = append(lines, fmt.Sprintf("#%d", i+1))
+		for j, line := range lines {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(slot.x+4), float64(slot.y+4+j*16))
+			op.ColorScale.ScaleWithColor(color.RGBA{R: 255, G: 215, B: 0, A: 255})
+			text.Draw(screen, line, g.font, op)
+		}
+		if g.state.phase == PhaseBuild && i == g.state.selectedForeman {
+			vector.StrokeRect(screen, float32(slot.x), float32(slot.y), float32(slot.w), float32(slot.h), 3, color.RGBA{R: 255, G: 255, B: 0, A: 255}, false)
+			g.drawScreenButton(screen, foremanSellButton(slot), fmt.Sprintf("Sell $%d", foremanSellValue(card)), color.RGBA{R: 200, G: 100, B: 100, A: 255})
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package game
 
 import (
+	"fmt"
 	"image/color"
 
+	"github/brensch/game/pkg/sim"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -11,7 +15,78 @@ func (g *Game) drawRoundEndLayout(screen *ebiten.Image) {
 	// Clear screen or draw background
 	vector.DrawFilledRect(screen, 0, 0, float32(g.screenWidth), float32(g.height), color.RGBA{R: 50, G: 50, B: 50, A: 255}, false)
 
+	// Itemize the round's earnings above the Next Round button. The game over
+	// screen shares this layout but has nothing to pay out.
+	if g.state.phase != PhaseGameOver {
+		g.drawRoundEarnings(screen)
+		g.drawRewards(screen)
+	}
+
 	// Draw info bar at bottom
 	g.drawInfoBar(screen, g.height-g.topPanelHeight)
 
 }
+
+// drawRoundEarnings lists the money earned over the round just cleared.
+func (g *Game) drawRoundEarnings(screen *ebiten.Image) {
+	e := g.state.earnings
+	lines := []string{
+		fmt.Sprintf("Round %d cleared", g.state.round),
+		"",
+		fmt.Sprintf("Deliveries      $%d", e.Deliveries),
+		fmt.Sprintf("Round reward    $%d", e.Reward),
+		fmt.Sprintf("Interest        $%d", e.Interest),
+		fmt.Sprintf("Target bonus    $%d", e.Bonus),
+		"",
+		fmt.Sprintf("Total earned    $%d", e.Total()),
+	}
+	y := g.height/2 + 50 - len(lines)*20 - 20
+	for _, line := range lines {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(g.screenWidth/2-100), float64(y))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, line, g.font, op)
+		y += 20
+	}
+}
+
+// rewardsLayout returns where each reward card sits on the round end screen,
+// side by side below the top panel.
+func (g *Game) rewardsLayout() []screenRect {
+	margin := 20
+	n := len(g.state.rewards)
+	if n == 0 {
+		return nil
+	}
+	cardW := (g.screenWidth - (n+1)*margin) / n
+	var cards []screenRect
+	for i := 0; i < n; i++ {
+		cards = append(cards, screenRect{x: margin + i*(cardW+margin), y: g.topPanelHeight + 25, w: cardW, h: 90})
+	}
+	return cards
+}
+
+// drawRewards draws the object deck rewards on offer, one of which the player
+// may claim.
+func (g *Game) drawRewards(screen *ebiten.Image) {
+	cards := g.rewardsLayout()
+	if len(cards) == 0 {
+		return
+	}
+	title := &text.DrawOptions{}
+	title.GeoM.Translate(20, float64(g.topPanelHeight))
+	title.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, "Choose a reward for your object deck", g.font, title)
+	for i, reward := range g.state.rewards {
+		card := cards[i]
+		vector.DrawFilledRect(screen, float32(card.x), float32(card.y), float32(card.w), float32(card.h), color.RGBA{R: 80, G: 80, B: 80, A: 255}, false)
+		objType := sim.ObjectTypeOf(reward.Object.Type)
+		drawObject(screen, float32(card.x+20), float32(card.y+20), 16, objType.Color, objType.Shape, reward.Object.Edition, g.frameCount)
+		for j, line := range wrapText(reward.label(), (card.w-10)/9) {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(card.x+5), float64(card.y+35+j*16))
+			op.ColorScale.ScaleWithColor(color.White)
+			text.Draw(screen, line, g.font, op)
+		}
+	}
+}
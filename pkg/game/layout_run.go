@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"image/color"
+	"math/big"
+
+	"github/brensch/game/pkg/sim"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font/gofont/goregular"
@@ -14,10 +18,11 @@ import (
 func (g *Game) drawRunLayout(screen *ebiten.Image) {
 	// Draw factory floor
 	g.drawFactoryFloor(screen)
+	g.drawForemen(screen)
 
 	// Draw placed machines
 	for pos, ms := range g.state.machines {
-		if ms == nil || ms.Machine == nil {
+		if ms == nil || ms.Machine == nil || !g.state.isAnchor(pos) {
 			continue
 		}
 		col := pos % gridCols
@@ -27,9 +32,14 @@ func (g *Game) drawRunLayout(screen *ebiten.Image) {
 		}
 		x := g.gridStartX + (col-1)*(g.cellSize+g.gridMargin)
 		y := g.gridStartY + (row-1)*(g.cellSize+g.gridMargin)
-		vector.DrawFilledRect(screen, float32(x), float32(y), float32(g.cellSize), float32(g.cellSize), ms.Machine.GetColor(), false)
+		g.drawMachineTile(screen, x, y, ms.Machine, ms.Orientation)
+	}
 
-		g.drawArrow(screen, float32(x), float32(y), ms.Orientation)
+	// Draw objects left waiting on empty cells, dimmed
+	for _, anim := range g.state.leftovers {
+		size := float64(g.cellSize) / 4
+		dimmed := color.RGBA{R: anim.Color.R / 2, G: anim.Color.G / 2, B: anim.Color.B / 2, A: 255}
+		drawObjectShape(screen, float32(anim.EndX), float32(anim.EndY), float32(size), dimmed, anim.Shape)
 	}
 
 	// Draw animations
@@ -38,9 +48,28 @@ func (g *Game) drawRunLayout(screen *ebiten.Image) {
 		x := anim.StartX + (anim.EndX-anim.StartX)*progress
 		y := anim.StartY + (anim.EndY-anim.StartY)*progress
 		size := float64(g.cellSize) / 4
-		vector.DrawFilledRect(screen, float32(x-size/2), float32(y-size/2), float32(size), float32(size), anim.Color, false)
+		if anim.Retrigger > 0 {
+			// A ring that grows as the retrigger plays out, labelled with how
+			// many times over the machine has gone off
+			radius := size/2 + size*progress
+			vector.StrokeCircle(screen, float32(x), float32(y), float32(radius), 2, color.RGBA{R: 150, G: 100, B: 255, A: 255}, false)
+			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("x%d", anim.Retrigger+1), int(x+radius), int(y-radius))
+			continue
+		}
+		drawObject(screen, float32(x), float32(y), float32(size), anim.Color, anim.Shape, anim.Edition, g.frameCount)
+		if anim.Buffed {
+			vector.StrokeRect(screen, float32(x-size/2-2), float32(y-size/2-2), float32(size+4), float32(size+4), 2, color.RGBA{R: 255, G: 255, B: 0, A: 255}, false)
+		}
+		// Cross out objects that are leaving the board
+		if anim.Fate == sim.FateSpilled || anim.Fate == sim.FateLost {
+			red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+			vector.StrokeLine(screen, float32(x-size/2), float32(y-size/2), float32(x+size/2), float32(y+size/2), 2, red, false)
+			vector.StrokeLine(screen, float32(x-size/2), float32(y+size/2), float32(x+size/2), float32(y-size/2), 2, red, false)
+		}
 	}
 
+	g.drawRunError(screen)
+
 	// Draw bottom panel
 	vector.DrawFilledRect(screen, 0, float32(g.bottomY), float32(g.screenWidth), float32(g.bottomHeight), color.RGBA{R: 80, G: 80, B: 80, A: 255}, false)
 
@@ -52,10 +81,10 @@ func (g *Game) drawRunLayout(screen *ebiten.Image) {
 	faceLarge := &text.GoTextFace{Source: source, Size: 24}
 	y := g.bottomY + 10
 
-	if len(g.state.animations) == 0 && g.state.roundScore > 0 {
+	if len(g.state.animations) == 0 && g.state.roundScore.Sign() > 0 {
 		// Run ended, show the total points earned
-		total := g.state.roundScore * g.state.multiplier
-		totalStr := fmt.Sprintf("%d", total)
+		total := new(big.Int).Mul(g.state.roundScore, g.state.multiplier)
+		totalStr := formatScore(total)
 		totalWidth, _ := text.Measure(totalStr, faceLarge, 0)
 		totalBoxW := int(totalWidth) + 20
 		totalBoxH := 40
@@ -67,7 +96,7 @@ func (g *Game) drawRunLayout(screen *ebiten.Image) {
 		text.Draw(screen, totalStr, faceLarge, opTotal)
 	} else {
 		// Running, show Run Score with boxes
-		smallBoxW := 60
+		smallBoxW := 90 // Room for a score like 1.23e45
 		smallBoxH := 40
 		gap := 10
 		xW := 10
@@ -98,14 +127,14 @@ func (g *Game) drawRunLayout(screen *ebiten.Image) {
 		vector.DrawFilledRect(screen, float32(multBoxX), float32(y), float32(smallBoxW), float32(smallBoxH), color.RGBA{R: 0, G: 0, B: 0, A: 255}, false)
 
 		// Base text
-		baseStr := fmt.Sprintf("%d", g.state.roundScore)
+		baseStr := formatScore(g.state.roundScore)
 		opBase := &text.DrawOptions{}
 		opBase.GeoM.Translate(float64(baseBoxX+10), float64(y+8))
 		opBase.ColorScale.ScaleWithColor(color.White)
 		text.Draw(screen, baseStr, faceLarge, opBase)
 
 		// Mult text
-		multStr := fmt.Sprintf("%d", g.state.multiplier)
+		multStr := formatScore(g.state.multiplier)
 		opMult := &text.DrawOptions{}
 		opMult.GeoM.Translate(float64(multBoxX+10), float64(y+8))
 		opMult.ColorScale.ScaleWithColor(color.White)
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+
+	"github/brensch/game/pkg/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// shopLayout returns where each offer card, the foreman card, the reroll
+// button, the done button and the deck button sit on the shop screen. Cards
+// are laid out two to a row, with the foreman card across the full width
+// below them.
+func (g *Game) shopLayout() (cards []screenRect, foreman, reroll, done, deck screenRect) {
+	margin := 20
+	cardW := (g.screenWidth - 3*margin) / 2
+	cardH := 140
+	top := g.topPanelHeight
+	for i := range g.state.shop.Offers {
+		col := i % 2
+		row := i / 2
+		cards = append(cards, screenRect{
+			x: margin + col*(cardW+margin),
+			y: top + row*(cardH+margin),
+			w: cardW,
+			h: cardH,
+		})
+	}
+	rows := (len(g.state.shop.Offers) + 1) / 2
+	foreman = screenRect{x: margin, y: top + rows*(cardH+margin), w: 2*cardW + margin, h: 60}
+	buttonsY := foreman.y + foreman.h + margin
+	reroll = screenRect{x: margin, y: buttonsY, w: cardW, h: 40}
+	done = screenRect{x: 2*margin + cardW, y: buttonsY, w: cardW, h: 40}
+	deck = screenRect{x: margin, y: buttonsY + 40 + margin, w: 2*cardW + margin, h: 40}
+	return cards, foreman, reroll, done, deck
+}
+
+func (g *Game) drawShopLayout(screen *ebiten.Image) {
+	vector.DrawFilledRect(screen, 0, 0, float32(g.screenWidth), float32(g.height), color.RGBA{R: 50, G: 50, B: 50, A: 255}, false)
+	if g.state.shop == nil {
+		return
+	}
+
+	title := &text.DrawOptions{}
+	title.GeoM.Translate(20, float64(g.topPanelHeight/2-10))
+	title.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, fmt.Sprintf("Shop - $%d to spend", g.state.money), g.font, title)
+
+	cards, foremanCard, reroll, done, deck := g.shopLayout()
+	for i, offer := range g.state.shop.Offers {
+		card := cards[i]
+		reason := g.state.offerUnavailable(offer)
+		background := color.RGBA{R: 80, G: 80, B: 80, A: 255}
+		textColor := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+		if reason != "" {
+			background = color.RGBA{R: 60, G: 60, B: 60, A: 255}
+			textColor = color.RGBA{R: 140, G: 140, B: 140, A: 255}
+		}
+		vector.DrawFilledRect(screen, float32(card.x), float32(card.y), float32(card.w), float32(card.h), background, false)
+		swatch := sim.SpecOf(offer.Card.Machine).Color
+		if reason != "" {
+			swatch = color.RGBA{R: swatch.R / 2, G: swatch.G / 2, B: swatch.B / 2, A: 255}
+		}
+		vector.DrawFilledRect(screen, float32(card.x+10), float32(card.y+10), 30, 30, swatch, false)
+
+		description := wrapText(sim.SpecOf(offer.Card.Machine).Description, card.w/9)
+		if len(description) > 2 {
+			description = description[:2]
+		}
+		lines := append([]string{sim.SpecOf(offer.Card.Machine).Name, fmt.Sprintf("$%d %s", offer.Price, sim.RarityName(sim.SpecOf(offer.Card.Machine).Rarity))}, description...)
+		for j, line := range lines {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(card.x+50), float64(card.y+10+j*20))
+			if j >= 2 {
+				op.GeoM.Translate(-40, 10)
+			}
+			op.ColorScale.ScaleWithColor(textColor)
+			text.Draw(screen, line, g.font, op)
+		}
+		if reason != "" {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(card.x+10), float64(card.y+card.h-25))
+			op.ColorScale.ScaleWithColor(color.RGBA{R: 255, G: 99, B: 71, A: 255})
+			text.Draw(screen, reason, g.font, op)
+		}
+	}
+
+	// The foreman card for sale
+	offer := g.state.shop.Foreman
+	reason := g.state.foremanUnavailable(offer)
+	background := color.RGBA{R: 70, G: 60, B: 40, A: 255}
+	textColor := color.RGBA{R: 255, G: 215, B: 0, A: 255}
+	if reason != "" {
+		background = color.RGBA{R: 60, G: 60, B: 60, A: 255}
+		textColor = color.RGBA{R: 140, G: 140, B: 140, A: 255}
+	}
+	vector.DrawFilledRect(screen, float32(foremanCard.x), float32(foremanCard.y), float32(foremanCard.w), float32(foremanCard.h), background, false)
+	heading := fmt.Sprintf("Foreman: %s $%d %s", offer.Spec.Name, offer.Price, sim.RarityName(offer.Spec.Rarity))
+	if reason != "" {
+		heading += " - " + reason
+	}
+	for j, line := range []string{heading, offer.Spec.Description} {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(foremanCard.x+10), float64(foremanCard.y+10+j*20))
+		op.ColorScale.ScaleWithColor(textColor)
+		text.Draw(screen, line, g.font, op)
+	}
+
+	rerollColor := color.RGBA{R: 200, G: 100, B: 200, A: 255}
+	if g.state.shop.RerollCost > g.state.money {
+		rerollColor = color.RGBA{R: 100, G: 100, B: 100, A: 255}
+	}
+	g.drawScreenButton(screen, reroll, fmt.Sprintf("Reroll $%d", g.state.shop.RerollCost), rerollColor)
+	g.drawScreenButton(screen, done, "Done", color.RGBA{R: 100, G: 200, B: 100, A: 255})
+	g.drawScreenButton(screen, deck, fmt.Sprintf("Deck - %d to draw", len(g.state.deck.DrawPile)), color.RGBA{R: 100, G: 100, B: 200, A: 255})
+
+	// Show what the player already has
+	inventoryY := deck.y + deck.h + 20
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(20, float64(inventoryY))
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, fmt.Sprintf("Inventory %d/%d", len(g.state.inventory), maxInventory), g.font, op)
+	for i, ms := range g.state.inventory {
+		x := 20 + i*(g.cellSize+g.gridMargin)
+		vector.DrawFilledRect(screen, float32(x), float32(inventoryY+25), float32(g.cellSize), float32(g.cellSize), sim.SpecOf(ms.Machine).Color, false)
+	}
+
+	// Draw info bar at bottom
+	g.drawInfoBar(screen, g.height-g.topPanelHeight)
+}
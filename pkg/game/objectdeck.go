@@ -0,0 +1,136 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	"github/brensch/game/pkg/sim"
+)
+
+const (
+	handSize     = 5 // Objects drawn from the object deck for each run
+	upgradeValue = 2 // Value an upgrade reward adds to an object
+)
+
+// objectDecksJSON defines the starting object decks by name. Each entry names
+// an object type by its key, and may leave out count to add one.
+//
+//go:embed objectdecks.json
+var objectDecksJSON []byte
+
+// objectDeckEntry is one line of a starting object deck definition.
+type objectDeckEntry struct {
+	Object string `json:"object"`
+	Count  *int   `json:"count"`
+}
+
+// startingObjects returns the objects in the named deck from objectdecks.json,
+// each worth its type's base value.
+func startingObjects(name string) ([]*sim.Object, error) {
+	var decks map[string][]objectDeckEntry
+	if err := json.Unmarshal(objectDecksJSON, &decks); err != nil {
+		return nil, fmt.Errorf("reading objectdecks.json: %w", err)
+	}
+	entries, ok := decks[name]
+	if !ok {
+		return nil, fmt.Errorf("no object deck named %q in objectdecks.json", name)
+	}
+	var objects []*sim.Object
+	for _, entry := range entries {
+		objType, ok := sim.ObjectTypeByKey(entry.Object)
+		if !ok {
+			return nil, fmt.Errorf("object deck %q: unknown object %q", name, entry.Object)
+		}
+		count := 1
+		if entry.Count != nil {
+			count = *entry.Count
+		}
+		for i := 0; i < count; i++ {
+			objects = append(objects, newTemplate(objType, sim.EditionNone))
+		}
+	}
+	return objects, nil
+}
+
+// newTemplate returns an object deck entry of a type and edition, worth the
+// type's base value.
+func newTemplate(objType sim.ObjectType, edition sim.Edition) *sim.Object {
+	return &sim.Object{
+		Type:    objType,
+		Edition: edition,
+		Score:   &sim.Score{Value: sim.ObjectTypeOf(objType).BaseValue, MultMult: 1},
+	}
+}
+
+// ObjectDeck is the player's deck of objects the miners feed into the
+// factory. Each entry is a template the miners copy. Every entry is always in
+// exactly one of the draw pile, the discard pile or the hand, which is what
+// the next run will feed in, in order. The top of the draw pile is the end of
+// DrawPile.
+type ObjectDeck struct {
+	DrawPile    []*sim.Object
+	DiscardPile []*sim.Object
+	Hand        []*sim.Object
+}
+
+// newObjectDeck returns a shuffled object deck holding the objects given, with
+// the first hand drawn.
+func newObjectDeck(rng *rand.Rand, objects []*sim.Object) *ObjectDeck {
+	d := &ObjectDeck{DrawPile: append([]*sim.Object(nil), objects...)}
+	shuffleObjects(rng, d.DrawPile)
+	d.dealHand(rng)
+	return d
+}
+
+// dealHand discards the current hand and draws the next one from the top of
+// the draw pile. When the draw pile runs out the discard pile is shuffled to
+// form a new one, so the hand may come up short only when the deck is small.
+func (d *ObjectDeck) dealHand(rng *rand.Rand) {
+	d.DiscardPile = append(d.DiscardPile, d.Hand...)
+	d.Hand = nil
+	for len(d.Hand) < handSize {
+		if len(d.DrawPile) == 0 {
+			d.DrawPile, d.DiscardPile = d.DiscardPile, nil
+			shuffleObjects(rng, d.DrawPile)
+		}
+		if len(d.DrawPile) == 0 {
+			return
+		}
+		d.Hand = append(d.Hand, d.DrawPile[len(d.DrawPile)-1])
+		d.DrawPile = d.DrawPile[:len(d.DrawPile)-1]
+	}
+}
+
+// objects returns every entry in the deck, wherever it is.
+func (d *ObjectDeck) objects() []*sim.Object {
+	all := make([]*sim.Object, 0, len(d.DrawPile)+len(d.DiscardPile)+len(d.Hand))
+	all = append(all, d.DrawPile...)
+	all = append(all, d.DiscardPile...)
+	return append(all, d.Hand...)
+}
+
+// add puts a new entry on the discard pile, to be shuffled in with the rest.
+func (d *ObjectDeck) add(obj *sim.Object) {
+	d.DiscardPile = append(d.DiscardPile, obj)
+}
+
+// remove takes an entry out of the deck, wherever it is.
+func (d *ObjectDeck) remove(obj *sim.Object) {
+	for _, pile := range []*[]*sim.Object{&d.DrawPile, &d.DiscardPile, &d.Hand} {
+		for i, o := range *pile {
+			if o == obj {
+				*pile = append((*pile)[:i:i], (*pile)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// shuffleObjects shuffles a pile in place.
+func shuffleObjects(rng *rand.Rand, pile []*sim.Object) {
+	rng.Shuffle(len(pile), func(i, j int) {
+		pile[i], pile[j] = pile[j], pile[i]
+	})
+}
@@ -0,0 +1,12 @@
+package game
+
+// handleDeck returns to the screen the deck was opened from when the player
+// taps the back button.
+func (g *Game) handleDeck() {
+	if !g.lastInput.JustPressed {
+		return
+	}
+	if g.deckLayout().contains(g.lastInput.X, g.lastInput.Y) {
+		g.state.phase = g.state.previousPhase
+	}
+}
@@ -1,5 +1,21 @@
 package game
 
+import "fmt"
+
+// sellZone returns the drop zone in the bottom-right corner that sells a
+// placed machine dragged onto it. It covers the Start Run button, which is
+// hidden while the zone is showing.
+func (g *Game) sellZone() screenRect {
+	return screenRect{x: g.screenWidth - 10 - buttonWidth, y: g.bottomY + 10, w: buttonWidth, h: g.bottomHeight - 20}
+}
+
+// sellZoneActive reports whether a placed machine is being dragged, so the
+// sell zone is showing.
+func (g *Game) sellZoneActive() bool {
+	dragging := g.getDraggingMachine()
+	return dragging != nil && dragging.IsPlaced
+}
+
 func (g *Game) handleDragAndDrop() {
 	cx, cy := g.lastInput.X, g.lastInput.Y
 
@@ -7,21 +23,11 @@ func (g *Game) handleDragAndDrop() {
 	// Update button visibility and position
 	if selected != nil && selected.IsPlaced {
 		g.state.buttons["sell"].States[PhaseBuild].Visible = true
+		g.state.buttons["sell"].States[PhaseBuild].Text = fmt.Sprintf("Sell $%d", g.state.sellValue(selected))
 	} else {
 		g.state.buttons["sell"].States[PhaseBuild].Visible = false
 	}
-	hasSelectedInventory := false
-	for _, sel := range g.state.inventorySelected {
-		if sel {
-			hasSelectedInventory = true
-			break
-		}
-	}
-	if hasSelectedInventory && g.state.restocksLeft > 0 {
-		g.state.buttons["restock"].States[PhaseBuild].Visible = true
-	} else {
-		g.state.buttons["restock"].States[PhaseBuild].Visible = false
-	}
+	g.state.buttons["run"].States[PhaseBuild].Visible = !g.sellZoneActive()
 	// Position buttons below selected machine
 	selectedPos := -1
 	if selected != nil {
@@ -39,14 +45,16 @@ func (g *Game) handleDragAndDrop() {
 			// Calculate screen position of the selected machine
 			machineX := g.gridStartX + (col-1)*(g.cellSize+g.gridMargin)
 			machineY := g.gridStartY + (row-1)*(g.cellSize+g.gridMargin)
+			machineW, machineH := g.machineSize(selected.Machine, selected.Orientation)
 
 			// Position buttons below the selected machine, offset from grid alignment
-			buttonSize := g.cellSize                             // Make buttons bigger (full cell size instead of half)
-			buttonY := machineY + g.cellSize + g.gridMargin + 10 // Extra offset from grid
+			buttonSize := g.cellSize                           // Make buttons bigger (full cell size instead of half)
+			buttonY := machineY + machineH + g.gridMargin + 10 // Extra offset from grid
 
 			// Center the buttons below the machine
-			machineCenterX := machineX + g.cellSize/2
-			totalButtonWidth := 3*buttonSize + 2*5 // 3 buttons + 2 gaps of 5px
+			machineCenterX := machineX + machineW/2
+			sellWidth := buttonSize * 3 / 2                    // Wide enough for the sell value
+			totalButtonWidth := 2*buttonSize + sellWidth + 2*5 // 3 buttons + 2 gaps of 5px
 			startX := machineCenterX - totalButtonWidth/2
 
 			// Update rotate left button
@@ -71,7 +79,7 @@ func (g *Game) handleDragAndDrop() {
 			if sellBtn, exists := g.state.buttons["sell"]; exists {
 				sellBtn.X = startX + 2*buttonSize + 2*5
 				sellBtn.Y = buttonY
-				sellBtn.Width = buttonSize
+				sellBtn.Width = sellWidth
 				sellBtn.Height = buttonSize
 				sellBtn.States[PhaseBuild].Visible = true
 			}
@@ -152,7 +160,9 @@ func (g *Game) handleDragAndDrop() {
 	if g.lastInput.IsDragging {
 		selected := g.getSelectedMachine()
 		if selected != nil {
-			if selected.IsPlaced && selected.RunAdded == g.state.runsLeft {
+			if selected.IsPlaced {
+				// Any placed machine can be dragged to the sell zone, though
+				// only those placed this run can be moved on the grid.
 				selected.BeingDragged = true
 				pos := g.getPos(selected)
 				selected.OriginalPos = pos
@@ -166,6 +176,17 @@ func (g *Game) handleDragAndDrop() {
 
 	if g.lastInput.JustReleased {
 		dragging := g.getDraggingMachine()
+		if dragging != nil && dragging.IsPlaced && g.sellZone().contains(cx, cy) {
+			// Dropped on the sell zone
+			dragging.BeingDragged = false
+			g.state.sellMachine(dragging)
+			return
+		}
+		if dragging != nil && dragging.IsPlaced && dragging.RunAdded != g.state.runsLeft {
+			// Machines from earlier runs stay where they are
+			dragging.BeingDragged = false
+			return
+		}
 		if dragging != nil {
 			// Place at cursor position
 			gridX, gridY := -1, -1
@@ -175,7 +196,7 @@ func (g *Game) handleDragAndDrop() {
 					y := g.gridStartY + r*(g.cellSize+g.gridMargin)
 					if cx >= x-10 && cx <= x+g.cellSize+10 && cy >= y-10 && cy <= y+g.cellSize+10 {
 						position := (r+1)*gridCols + (c + 1)
-						if g.state.machines[position] == nil {
+						if g.state.canPlace(dragging, position) {
 							gridX, gridY = c, r
 						}
 						break
@@ -188,32 +209,14 @@ func (g *Game) handleDragAndDrop() {
 			if gridX != -1 {
 				var placedMS *MachineState
 				if !dragging.IsPlaced {
-					// Create a new instance for placed machine
-					newMS := &MachineState{
-						Machine:      dragging.Machine,
-						Orientation:  dragging.Orientation,
-						BeingDragged: false,
-						IsPlaced:     true,
-						RunAdded:     g.state.runsLeft,
-					}
+					// Place the inventory machine itself, card and all
 					position := (gridY+1)*gridCols + (gridX + 1)
-					g.state.machines[position] = newMS
-					placedMS = newMS
-					// Remove from inventory
-					for i, ms := range g.state.inventory {
-						if ms == dragging {
-							g.state.inventory = append(g.state.inventory[:i], g.state.inventory[i+1:]...)
-							g.state.inventorySelected = append(g.state.inventorySelected[:i], g.state.inventorySelected[i+1:]...)
-							break
-						}
-					}
+					g.state.placeFromInventory(dragging, position)
+					placedMS = dragging
 				} else {
 					// Moving existing placed machine
 					position := (gridY+1)*gridCols + (gridX + 1)
-					g.state.machines[position] = dragging
-					if position != dragging.OriginalPos {
-						g.state.machines[dragging.OriginalPos] = nil
-					}
+					g.state.placeMachine(dragging, position)
 					placedMS = dragging
 				}
 				// Select the newly placed machine
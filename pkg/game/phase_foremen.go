@@ -0,0 +1,33 @@
+package game
+
+// handleForemen handles taps on the foreman panel during the build phase.
+// Tapping a card selects it; tapping another slot then moves the selected
+// card there, and tapping its sell button sells it. It reports whether the
+// tap landed on the panel.
+func (g *Game) handleForemen() bool {
+	if !g.lastInput.JustPressed {
+		return false
+	}
+	cx, cy := g.lastInput.X, g.lastInput.Y
+	for i, slot := range g.foremenLayout() {
+		if !slot.contains(cx, cy) {
+			continue
+		}
+		selected := g.state.selectedForeman
+		switch {
+		case selected == i && foremanSellButton(slot).contains(cx, cy):
+			g.state.sellForeman(i)
+		case selected == i:
+			g.state.selectedForeman = -1
+		case selected >= 0 && i < len(g.state.foremen):
+			g.state.moveForeman(selected, i)
+			g.state.selectedForeman = -1
+		case i < len(g.state.foremen):
+			g.state.selectedForeman = i
+		default:
+			g.state.selectedForeman = -1
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package game
+
+// handleRoundEnd claims the reward the player taps on the round end screen.
+func (g *Game) handleRoundEnd() {
+	if !g.lastInput.JustPressed {
+		return
+	}
+	for i, card := range g.rewardsLayout() {
+		if card.contains(g.lastInput.X, g.lastInput.Y) {
+			g.state.claimReward(i)
+			return
+		}
+	}
+}
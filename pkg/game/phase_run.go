@@ -1,12 +1,35 @@
 package game
 
 import (
-	"image/color"
+	"math/big"
+
+	"github/brensch/game/pkg/sim"
 )
 
+// runResult is what simulating a run produces, handed back to the game loop
+// by the goroutine that simulated it.
+type runResult struct {
+	machines []*sim.MachineState // The copies the run was simulated on
+	changes  [][]*sim.Change
+	total    sim.RunTotal
+	err      error
+}
+
 func (g *Game) handleRunPhase() {
 	if g.state.allChanges == nil {
 		// Still calculating
+		select {
+		case result := <-g.state.runResults:
+			// Keep what the run left on the machines, such as effects and
+			// memory. The run still animates up to the point any problem was
+			// found, and the error stays on screen until the next run starts.
+			// It scores whatever happened before then.
+			sim.SyncMachines(g.state.simMachines(), result.machines)
+			g.state.runError = result.err
+			g.state.runTotal = result.total
+			g.state.allChanges = result.changes
+		default:
+		}
 		return
 	}
 
@@ -14,11 +37,14 @@ func (g *Game) handleRunPhase() {
 	for _, anim := range g.state.animations {
 		anim.Elapsed++
 	}
-	// Remove completed animations
+	// Remove completed animations, leaving objects that came to rest on an
+	// empty cell drawn there for the rest of the run
 	newAnims := []*Animation{}
 	for _, anim := range g.state.animations {
 		if anim.Elapsed < anim.Duration {
 			newAnims = append(newAnims, anim)
+		} else if anim.Fate == sim.FateWaiting {
+			g.state.leftovers = append(g.state.leftovers, anim)
 		}
 	}
 	g.state.animations = newAnims
@@ -29,45 +55,77 @@ func (g *Game) handleRunPhase() {
 		if g.state.animationTick >= len(changes) || len(changes) == 0 {
 			if g.state.endRunDelay == 0 {
 				g.state.endRunDelay = 30
+				// Show the run's final total, foremen included
+				g.state.roundScore, g.state.multiplier = g.state.runTotal.RoundScore, g.state.runTotal.Multiplier
 			}
 		} else {
 			// Start new tick
 			tickChanges := changes[g.state.animationTick]
 			g.state.animations = []*Animation{}
-			// Accumulate scores
+			// Show the score of the run so far, before the foremen's end of
+			// run bonuses, and note any recipes discovered
+			partial := sim.ScoreRun(changes[:g.state.animationTick+1], nil)
+			g.state.roundScore, g.state.multiplier = partial.RoundScore, partial.Multiplier
 			for _, ch := range tickChanges {
-				if ch.Score != nil {
-					g.state.roundScore += ch.Score.Value
-					g.state.multiplier += ch.Score.MultAdd
-					g.state.multiplier *= ch.Score.MultMult
+				g.state.money += ch.Payout
+				g.state.earnings.Deliveries += ch.Payout
+				if ch.Recipe != "" {
+					g.discovered[ch.Recipe] = true
 				}
 			}
+			// Animate each object from wherever its ID (or the object it was
+			// made from) last came to rest, so splits and merges stay attached
+			// to the items they came from.
+			resting := make(map[int][2]float64)
 			for _, ch := range tickChanges {
-				if ch.StartObject == nil || ch.EndObject == nil {
+				if ch.StartObject == nil {
+					continue
+				}
+				// Retriggers flash on the machine that was retriggered rather
+				// than moving the object again.
+				if ch.Retrigger > 0 {
+					if ch.MachinePosition >= 0 {
+						x, y := g.cellCenter(ch.MachinePosition)
+						objType := sim.ObjectTypeOf(ch.StartObject.Type)
+						g.state.animations = append(g.state.animations, &Animation{
+							StartX: x, StartY: y,
+							EndX: x, EndY: y,
+							Color: objType.Color, Shape: objType.Shape, Edition: ch.StartObject.Edition,
+							Duration:  30.0 / g.state.animationSpeed,
+							ObjectID:  ch.StartObject.ID,
+							Retrigger: ch.Retrigger,
+						})
+					}
+					continue
+				}
+				// Whatever happens to it this tick, the start object no longer
+				// rests where it was.
+				delete(g.state.objectPositions, ch.StartObject.ID)
+				if ch.EndObject == nil {
 					continue
 				}
-				startGridX := ch.StartObject.GridPosition % gridCols
-				startGridY := ch.StartObject.GridPosition / gridCols
-				endGridX := ch.EndObject.GridPosition % gridCols
-				endGridY := ch.EndObject.GridPosition / gridCols
-				startX := float64(g.gridStartX + (startGridX-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
-				startY := float64(g.gridStartY + (startGridY-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
-				endX := float64(g.gridStartX + (endGridX-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
-				endY := float64(g.gridStartY + (endGridY-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
-				objColor := color.RGBA{R: 255, A: 255}
-				switch ch.StartObject.Type {
-				case ObjectGreen:
-					objColor.G = 255
-				case ObjectBlue:
-					objColor.B = 255
+				startX, startY := g.objectPosition(ch.StartObject)
+				endX, endY := startX, startY
+				if ch.EndObject.GridPosition != sim.OffGrid {
+					endX, endY = g.cellCenter(ch.EndObject.GridPosition)
 				}
+				if ch.Fate == sim.FateInPlay {
+					resting[ch.EndObject.ID] = [2]float64{endX, endY}
+				}
+				objType := sim.ObjectTypeOf(ch.StartObject.Type)
 				duration := 30.0 / g.state.animationSpeed // frames, decrease over time
 				g.state.animations = append(g.state.animations, &Animation{
 					StartX: startX, StartY: startY,
 					EndX: endX, EndY: endY,
-					Color: objColor, Duration: duration, Elapsed: 0,
+					Color: objType.Color, Shape: objType.Shape, Edition: ch.StartObject.Edition, Duration: duration, Elapsed: 0,
+					Buffed:   len(ch.Effects) > 0,
+					ObjectID: ch.EndObject.ID,
+					Fate:     ch.Fate,
 				})
 			}
+			for id, pos := range resting {
+				g.state.objectPositions[id] = pos
+			}
 			g.state.animationTick++
 			g.state.animationSpeed += 0.3 // speed up significantly each tick
 		}
@@ -80,30 +138,54 @@ func (g *Game) handleRunPhase() {
 			g.state.animationTick = 0
 			g.state.animationSpeed = 1.0
 			g.state.allChanges = nil
+			g.state.objectPositions = map[int][2]float64{}
+			g.state.leftovers = nil
 			g.state.runsLeft--
+			// Draw the objects for the next run
+			g.state.objectDeck.dealHand(g.state.rng)
 			// Add run score to total
-			g.state.totalScore += g.state.roundScore * g.state.multiplier
-			g.state.roundScore = 0
-			g.state.multiplier = 1
-			if g.state.runsLeft > 0 {
-				numToDeal := g.state.inventorySize - len(g.state.inventory)
-				if numToDeal > 0 {
-					newMachines := dealMachines(g.state.catalogue, numToDeal, g.state.runsLeft)
-					g.state.inventory = append(g.state.inventory, newMachines...)
-					g.state.inventorySelected = append(g.state.inventorySelected, make([]bool, numToDeal)...)
-				}
-			}
+			g.state.totalScore = new(big.Int).Add(g.state.totalScore, g.state.runTotal.Points())
+			g.state.roundScore = big.NewInt(0)
+			g.state.multiplier = big.NewInt(1)
 			if g.state.runsLeft == 0 {
-				if g.state.totalScore >= g.state.targetScore {
+				// Upgrades only last the round
+				sim.EndRound(g.state.simMachines())
+				if g.state.totalScore.Cmp(g.state.targetScore) >= 0 {
+					g.state.settleRound()
+					g.state.offerRewards()
 					g.state.phase = PhaseRoundEnd
 				} else {
 					g.state.gameOver = true
 					g.state.phase = PhaseGameOver
 				}
 			} else {
-				g.state.phase = PhaseBuild
+				// Spend money on new machines before the next run
+				g.state.openShop()
 			}
 			return
 		}
 	}
 }
+
+// objectPosition returns the screen position an object is resting at, falling
+// back to the centre of its cell for objects that have not been drawn yet.
+func (g *Game) objectPosition(obj *sim.Object) (float64, float64) {
+	if pos, ok := g.state.objectPositions[obj.ID]; ok {
+		return pos[0], pos[1]
+	}
+	for _, parent := range obj.ParentIDs {
+		if pos, ok := g.state.objectPositions[parent]; ok {
+			return pos[0], pos[1]
+		}
+	}
+	return g.cellCenter(obj.GridPosition)
+}
+
+// cellCenter returns the screen position of the centre of a grid cell.
+func (g *Game) cellCenter(pos int) (float64, float64) {
+	gridX := pos % gridCols
+	gridY := pos / gridCols
+	x := float64(g.gridStartX + (gridX-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
+	y := float64(g.gridStartY + (gridY-1)*(g.cellSize+g.gridMargin) + g.cellSize/2)
+	return x, y
+}
@@ -0,0 +1,28 @@
+package game
+
+// handleShop buys the offer or foreman the player taps, rerolls the shop,
+// shows the deck or leaves the shop for the build phase.
+func (g *Game) handleShop() {
+	if g.state.shop == nil || !g.lastInput.JustPressed {
+		return
+	}
+	cx, cy := g.lastInput.X, g.lastInput.Y
+	cards, foreman, reroll, done, deck := g.shopLayout()
+	for i, card := range cards {
+		if card.contains(cx, cy) {
+			g.state.buyOffer(g.state.shop.Offers[i])
+			return
+		}
+	}
+	switch {
+	case foreman.contains(cx, cy):
+		g.state.buyForeman(g.state.shop.Foreman)
+	case reroll.contains(cx, cy):
+		g.state.rerollShop()
+	case done.contains(cx, cy):
+		g.state.closeShop()
+	case deck.contains(cx, cy):
+		g.state.previousPhase = g.state.phase
+		g.state.phase = PhaseDeck
+	}
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"fmt"
+
+	"github/brensch/game/pkg/sim"
+)
+
+// editionChance is the one-in-n chance that an object offered as a reward
+// comes with a random edition.
+const editionChance = 3
+
+// RewardKind is what a reward does to the object deck.
+type RewardKind int
+
+const (
+	RewardAdd RewardKind = iota
+	RewardRemove
+	RewardUpgrade
+)
+
+// ObjectReward is a change to the object deck offered at the end of a round.
+// Object is the entry to add, or the entry in the deck to remove or upgrade.
+type ObjectReward struct {
+	Kind   RewardKind
+	Object *sim.Object
+}
+
+// label describes the reward on its card.
+func (r ObjectReward) label() string {
+	name := objectName(r.Object)
+	switch r.Kind {
+	case RewardAdd:
+		return "Add " + name
+	case RewardRemove:
+		return "Remove " + name
+	default:
+		return fmt.Sprintf("Upgrade %s +%d", name, upgradeValue)
+	}
+}
+
+// objectName names an object deck entry, with its edition if it has one.
+func objectName(obj *sim.Object) string {
+	name := sim.ObjectTypeOf(obj.Type).Name
+	if obj.Edition != sim.EditionNone {
+		name = sim.EditionName(obj.Edition) + " " + name
+	}
+	return name
+}
+
+// offerRewards sets out the rewards for clearing a round: a new mined object,
+// which may come with an edition, an upgrade to an entry already in the deck,
+// and the removal of one as long as the deck holds more than a hand.
+func (s *GameState) offerRewards() {
+	mined := sim.ObjectTypesTagged("mined")
+	edition := sim.EditionNone
+	if s.rng.Intn(editionChance) == 0 {
+		edition = sim.Editions[s.rng.Intn(len(sim.Editions))]
+	}
+	s.rewards = []ObjectReward{{Kind: RewardAdd, Object: newTemplate(mined[s.rng.Intn(len(mined))], edition)}}
+	objects := s.objectDeck.objects()
+	if len(objects) == 0 {
+		return
+	}
+	s.rewards = append(s.rewards, ObjectReward{Kind: RewardUpgrade, Object: objects[s.rng.Intn(len(objects))]})
+	if len(objects) > handSize {
+		s.rewards = append(s.rewards, ObjectReward{Kind: RewardRemove, Object: objects[s.rng.Intn(len(objects))]})
+	}
+}
+
+// claimReward applies one of the rewards on offer. Only one reward can be
+// claimed each round, so the rest are taken away.
+func (s *GameState) claimReward(i int) {
+	if i < 0 || i >= len(s.rewards) {
+		return
+	}
+	reward := s.rewards[i]
+	switch reward.Kind {
+	case RewardAdd:
+		s.objectDeck.add(reward.Object)
+	case RewardRemove:
+		s.objectDeck.remove(reward.Object)
+	case RewardUpgrade:
+		reward.Object.Score.Value += upgradeValue
+	}
+	s.rewards = nil
+}
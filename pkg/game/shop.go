@@ -0,0 +1,155 @@
+package game
+
+import (
+	"math/rand"
+
+	"github/brensch/game/pkg/sim"
+)
+
+const (
+	shopSize           = 4 // Machines on offer per visit
+	shopRerollBaseCost = 1 // Cost of the first reroll each visit
+	defaultPrice       = 3 // Price of a machine whose spec has no cost
+)
+
+// machinePrice returns the shop price of a machine, which is the cost in its
+// registered spec.
+func machinePrice(machine sim.MachineInterface) int {
+	if cost := sim.SpecOf(machine).Cost; cost > 0 {
+		return cost
+	}
+	return defaultPrice
+}
+
+// ShopOffer is a card from the deck for sale in the shop. Sold offers stay on
+// the shelf, out of stock, until the next reroll.
+type ShopOffer struct {
+	Card  *Card
+	Price int
+	Sold  bool
+}
+
+// Shop holds the state of the shop screen for one visit between runs.
+// Foreman is the one foreman card for sale. RerollCost is what the next
+// reroll costs; it goes up with every reroll and resets on the next visit.
+type Shop struct {
+	Offers     []*ShopOffer
+	Foreman    *ForemanOffer
+	RerollCost int
+}
+
+// newShop opens a shop stocked from the deck.
+func newShop(rng *rand.Rand, deck *Deck) *Shop {
+	shop := &Shop{RerollCost: shopRerollBaseCost}
+	shop.stock(rng, deck)
+	return shop
+}
+
+// stock discards every unsold offer and replaces the offers with up to
+// shopSize cards drawn from the deck, and a random foreman card.
+func (s *Shop) stock(rng *rand.Rand, deck *Deck) {
+	s.clear(deck)
+	specs := sim.ForemanSpecs()
+	spec := specs[rng.Intn(len(specs))]
+	s.Foreman = &ForemanOffer{Spec: spec, Price: foremanPrice(spec)}
+	for i := 0; i < shopSize; i++ {
+		card := deck.draw(rng)
+		if card == nil {
+			break
+		}
+		s.Offers = append(s.Offers, &ShopOffer{Card: card, Price: machinePrice(card.Machine)})
+	}
+}
+
+// clear empties the shelf, returning unsold offers to the discard pile. Sold
+// cards stay in play with the machines bought from them.
+func (s *Shop) clear(deck *Deck) {
+	for _, offer := range s.Offers {
+		if !offer.Sold {
+			deck.discard(offer.Card)
+		}
+	}
+	s.Offers = nil
+}
+
+// openShop moves the game to a freshly stocked shop.
+func (s *GameState) openShop() {
+	s.shop = newShop(s.rng, s.deck)
+	s.phase = PhaseShop
+}
+
+// rerollCost returns what the next shop reroll costs.
+func (s *GameState) rerollCost() int {
+	if s.shop == nil {
+		return shopRerollBaseCost
+	}
+	return s.shop.RerollCost
+}
+
+// offerUnavailable returns why an offer cannot be bought right now, or an
+// empty string if it can.
+func (s *GameState) offerUnavailable(offer *ShopOffer) string {
+	switch {
+	case offer.Sold:
+		return "Sold out"
+	case offer.Price > s.money:
+		return "Too expensive"
+	case len(s.inventory) >= maxInventory:
+		return "Inventory full"
+	default:
+		return ""
+	}
+}
+
+// buyOffer pays for an offer and puts a new machine for its card in the
+// inventory. It reports false, changing nothing, if the offer is unavailable.
+func (s *GameState) buyOffer(offer *ShopOffer) bool {
+	if s.offerUnavailable(offer) != "" {
+		return false
+	}
+	s.money -= offer.Price
+	offer.Sold = true
+	s.inventory = append(s.inventory, newMachineState(offer.Card, s.runsLeft))
+	s.inventorySelected = append(s.inventorySelected, false)
+	return true
+}
+
+// rerollShop pays for a reroll and restocks every offer. It reports false,
+// changing nothing, if the player cannot afford it.
+func (s *GameState) rerollShop() bool {
+	if s.shop == nil || s.shop.RerollCost > s.money {
+		return false
+	}
+	s.money -= s.shop.RerollCost
+	s.shop.RerollCost++
+	s.shop.stock(s.rng, s.deck)
+	return true
+}
+
+// sellValue returns what a placed machine sells for: half its shop price, plus
+// one for each of its upgrades (see sim.MachineState.Upgrades), less one for
+// every two runs it has been on the board, and never less than one.
+func (s *GameState) sellValue(ms *MachineState) int {
+	value := machinePrice(ms.Machine)/2 + ms.Upgrades()
+	value -= (ms.RunAdded - s.runsLeft) / 2
+	if value < 1 {
+		value = 1
+	}
+	return value
+}
+
+// sellMachine takes a placed machine off the grid, credits its sell value and
+// discards its card.
+func (s *GameState) sellMachine(ms *MachineState) {
+	s.money += s.sellValue(ms)
+	s.removeMachine(ms)
+	s.deck.discard(ms.Card)
+	ms.Selected = false
+}
+
+// closeShop discards the unsold offers and leaves the shop for the build phase.
+func (s *GameState) closeShop() {
+	s.shop.clear(s.deck)
+	s.shop = nil
+	s.phase = PhaseBuild
+}
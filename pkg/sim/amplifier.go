@@ -0,0 +1,104 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Amplifier represents an amplifier machine.
+type Amplifier struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "amplifier",
+		Name:         "Amplifier",
+		Description:  "Doubles the value of objects passing through and boosts nearby producers.",
+		Roles:        []MachineRole{RoleMover, RoleUpgrader},
+		Color:        color.RGBA{R: 255, G: 215, B: 0, A: 255}, // Gold
+		Rarity:       RarityRare,
+		Cost:         5,
+		DefaultCount: 1,
+		Machine:      &Amplifier{},
+	})
+}
+
+// New returns a fresh amplifier for a new placement.
+func (a *Amplifier) New() MachineInterface {
+	return &Amplifier{}
+}
+
+// GetType returns the machine type.
+func (a *Amplifier) GetType() MachineType {
+	return MachineAmplifier
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (a *Amplifier) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (a *Amplifier) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for amplifier.
+func (a *Amplifier) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			newValue := saturatingMul(obj.Score.Value, 2) // Double the value
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: &Score{Value: newValue, MultAdd: obj.Score.MultAdd, MultMult: obj.Score.MultMult}},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// Retrigger doubles the value of the object the amplifier sent on once more.
+func (a *Amplifier) Retrigger(change *Change) *Change {
+	if change.EndObject == nil || change.EndObject.Score == nil {
+		return nil
+	}
+	obj := *change.EndObject
+	score := *obj.Score
+	score.Value = saturatingMul(score.Value, 2)
+	obj.Score = &score
+	return &Change{StartObject: change.EndObject, EndObject: &obj}
+}
+
+// EmitEffects emits effects from amplifier.
+func (a *Amplifier) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Emit amplify effect to adjacent producers
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				for _, role := range SpecOf(machineState.Machine).Roles {
+					if role == RoleProducer {
+						emissions = append(emissions, EffectEmission{
+							TargetGridX: nc,
+							TargetGridY: nr,
+							Effect: &Effect{
+								Type:         EffectAmplifyValue,
+								Duration:     1,
+								DurationType: DurationTick,
+							},
+						})
+					}
+				}
+			}
+		}
+	}
+	return emissions
+}
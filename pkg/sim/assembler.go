@@ -0,0 +1,94 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Assembler represents an assembler machine. It covers two by two cells.
+type Assembler struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "assembler",
+		Name:         "Assembler",
+		Description:  "A large workshop that crafts three objects into one by recipe, such as three greens into an emerald. Parts with no recipe pass through.",
+		Roles:        []MachineRole{RoleMover, RoleUpgrader},
+		Color:        color.RGBA{R: 70, G: 130, B: 180, A: 255}, // Steel blue
+		Rarity:       RarityRare,
+		Cost:         8,
+		DefaultCount: 1,
+		Machine:      &Assembler{},
+	})
+}
+
+// New returns a fresh assembler for a new placement.
+func (a *Assembler) New() MachineInterface {
+	return &Assembler{}
+}
+
+// GetType returns the machine type.
+func (a *Assembler) GetType() MachineType {
+	return MachineAssembler
+}
+
+// GetFootprint returns the machine's size: two cells across its front and
+// two cells deep.
+func (a *Assembler) GetFootprint() (width, depth int) {
+	return 2, 2
+}
+
+// GetCapacity returns how many objects each of the machine's cells can hold,
+// enough for a full set of parts to gather on one cell.
+func (a *Assembler) GetCapacity() int {
+	return 3
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (a *Assembler) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (a *Assembler) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for assembler. Once three objects are
+// inside, it crafts them by recipe into one object that leaves by its first
+// front exit. Parts with no recipe are not assembled: the first passes
+// through as it is and the others wait for new parts.
+func (a *Assembler) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	inside := ObjectsOn(current, CoveredCells(position, a, orientation))
+	if len(inside) < 3 {
+		return nil
+	}
+	parts := inside[:3]
+	nextPos := ExitPositions(position, a, orientation, SideFront)[0]
+	recipe, ok := FindRecipe(parts)
+	if !ok {
+		obj := parts[0]
+		return []*Change{{
+			StartObject: obj,
+			EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+			Score:       nil,
+		}}
+	}
+	changes := []*Change{{
+		StartObject: parts[0],
+		EndObject:   recipe.Craft(parts, nextPos),
+		Score:       nil,
+		Recipe:      recipe.Key,
+	}}
+	for _, obj := range parts[1:] {
+		changes = append(changes, &Change{StartObject: obj, EndObject: nil, Score: nil})
+	}
+	return changes
+}
+
+// EmitEffects emits effects from assembler.
+func (a *Assembler) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Booster represents a booster machine.
+type Booster struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "booster",
+		Name:         "Booster",
+		Description:  "Moves objects forward and boosts the speed of adjacent machines.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 0, B: 255, G: 255, A: 255}, // Cyan
+		Rarity:       RarityRare,
+		Cost:         5,
+		DefaultCount: 1,
+		Machine:      &Booster{},
+	})
+}
+
+// New returns a fresh booster for a new placement.
+func (b *Booster) New() MachineInterface {
+	return &Booster{}
+}
+
+// GetType returns the machine type.
+func (b *Booster) GetType() MachineType {
+	return MachineBooster
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (b *Booster) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (b *Booster) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for booster.
+func (b *Booster) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from booster.
+func (b *Booster) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Emit speed buff to adjacent machines
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				emissions = append(emissions, EffectEmission{
+					TargetGridX: nc,
+					TargetGridY: nr,
+					Effect: &Effect{
+						Type:         EffectBuffSpeed,
+						Duration:     5,
+						DurationType: DurationTick,
+					},
+				})
+			}
+		}
+	}
+	return emissions
+}
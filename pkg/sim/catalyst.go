@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Catalyst represents a catalyst machine.
+type Catalyst struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "catalyst",
+		Name:         "Catalyst",
+		Description:  "Moves objects forward and increases efficiency of adjacent machines.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 255, G: 165, B: 0, A: 255}, // Orange
+		Rarity:       RarityRare,
+		Cost:         5,
+		DefaultCount: 1,
+		Machine:      &Catalyst{},
+	})
+}
+
+// New returns a fresh catalyst for a new placement.
+func (c *Catalyst) New() MachineInterface {
+	return &Catalyst{}
+}
+
+// GetType returns the machine type.
+func (c *Catalyst) GetType() MachineType {
+	return MachineCatalyst
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (c *Catalyst) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (c *Catalyst) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for catalyst.
+func (c *Catalyst) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from catalyst.
+func (c *Catalyst) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Emit efficiency buff to adjacent machines
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				emissions = append(emissions, EffectEmission{
+					TargetGridX: nc,
+					TargetGridY: nr,
+					Effect: &Effect{
+						Type:         EffectBuffEfficiency,
+						Duration:     10,
+						DurationType: DurationTick,
+					},
+				})
+			}
+		}
+	}
+	return emissions
+}
@@ -0,0 +1,165 @@
+package sim
+
+import (
+	"sort"
+)
+
+// DefaultCapacity is how many objects a machine's cell can hold at the end of
+// a tick unless the machine says otherwise.
+const DefaultCapacity = 1
+
+// capacityMachine is implemented by machines whose cell holds more than
+// DefaultCapacity objects.
+type capacityMachine interface {
+	GetCapacity() int
+}
+
+// CellCapacity returns how many objects the cell of a machine can hold.
+func CellCapacity(machine MachineInterface) int {
+	if m, ok := machine.(capacityMachine); ok {
+		return m.GetCapacity()
+	}
+	return DefaultCapacity
+}
+
+// resolveMoves decides which of the changes the machines proposed this tick
+// actually happen, given the objects on the floor at the start of the tick.
+// Each entry of proposals holds everything one machine wants to do this tick,
+// in grid order, and a machine's proposal either happens as a whole or is
+// blocked as a whole.
+//
+// All moves happen at once, so an object may move into a cell that another
+// object is leaving on the same tick. Conflicts are settled deterministically:
+//   - A machine that sends an object out of a side that is not one of its
+//     outputs, or into a machine through a side that is not one of that
+//     machine's inputs, is blocked (see portsAllow).
+//   - An object on the floor belongs to the first machine in grid order that
+//     claims it; later claims are blocked.
+//   - Two machines swapping objects head-on are both blocked.
+//   - Objects that stay where they are keep their place in a cell. If a cell
+//     would end the tick holding more than its capacity, the machine latest
+//     in grid order sending objects into it is blocked, and this repeats
+//     until every cell fits, so a blockage backs up along a chain. Negative
+//     objects take up no room, and an object a retrigger carries on with is
+//     only counted once.
+//
+// Objects whose machine was blocked, or that no machine took, stay where they
+// are and are recorded as blocked changes. The second result reports which
+// proposals were blocked, by index.
+func resolveMoves(current []*Object, proposals [][]*Change, machines []*MachineState) ([]*Change, []bool) {
+	onFloor := make(map[*Object]bool, len(current))
+	for _, obj := range current {
+		onFloor[obj] = true
+	}
+
+	blocked := make([]bool, len(proposals))
+	for i, group := range proposals {
+		for _, ch := range group {
+			if !portsAllow(ch, machines) {
+				blocked[i] = true
+			}
+		}
+	}
+
+	owner := make(map[*Object]int)
+	for i, group := range proposals {
+		for _, ch := range group {
+			if j, ok := owner[ch.StartObject]; ok && j != i {
+				blocked[i] = true
+			}
+		}
+		if blocked[i] {
+			continue
+		}
+		for _, ch := range group {
+			if onFloor[ch.StartObject] {
+				owner[ch.StartObject] = i
+			}
+		}
+	}
+
+	blockSwaps(proposals, blocked, onFloor)
+	for blockOverflow(current, proposals, blocked, owner, machines) {
+		// Each pass blocks one more machine until every cell fits.
+	}
+
+	var changes []*Change
+	for i, group := range proposals {
+		if !blocked[i] {
+			changes = append(changes, group...)
+		}
+	}
+	for _, obj := range current {
+		if i, ok := owner[obj]; ok && !blocked[i] {
+			continue
+		}
+		stay := *obj
+		changes = append(changes, &Change{StartObject: obj, EndObject: &stay, Blocked: true, MachinePosition: -1})
+	}
+	return changes, blocked
+}
+
+// blockSwaps blocks pairs of machines that would swap objects between their
+// cells, since the objects would have to pass through each other.
+func blockSwaps(proposals [][]*Change, blocked []bool, onFloor map[*Object]bool) {
+	type move struct{ from, to int }
+	moves := make([][]move, len(proposals))
+	for i, group := range proposals {
+		for _, ch := range group {
+			if onFloor[ch.StartObject] && ch.EndObject != nil && ch.Fate == FateInPlay && ch.StartObject.GridPosition != ch.EndObject.GridPosition {
+				moves[i] = append(moves[i], move{ch.StartObject.GridPosition, ch.EndObject.GridPosition})
+			}
+		}
+	}
+	for i := range proposals {
+		for j := i + 1; j < len(proposals); j++ {
+			for _, a := range moves[i] {
+				for _, b := range moves[j] {
+					if a.from == b.to && a.to == b.from {
+						blocked[i] = true
+						blocked[j] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// blockOverflow finds the first cell, in grid order, that would end the tick
+// holding more objects than it can, and blocks the last machine in grid order
+// sending objects into it. It reports whether it blocked anything.
+func blockOverflow(current []*Object, proposals [][]*Change, blocked []bool, owner map[*Object]int, machines []*MachineState) bool {
+	staying := make(map[int]int)
+	for _, obj := range current {
+		if i, ok := owner[obj]; (!ok || blocked[i]) && obj.takesRoom() {
+			staying[obj.GridPosition]++
+		}
+	}
+	incoming := make(map[int][]int)
+	for i, group := range proposals {
+		if blocked[i] {
+			continue
+		}
+		carried := carriedOn(group)
+		for _, ch := range group {
+			if ch.EndObject != nil && ch.Fate == FateInPlay && ch.EndObject.takesRoom() && !carried[ch.EndObject] {
+				incoming[ch.EndObject.GridPosition] = append(incoming[ch.EndObject.GridPosition], i)
+			}
+		}
+	}
+
+	cells := make([]int, 0, len(incoming))
+	for cell := range incoming {
+		cells = append(cells, cell)
+	}
+	sort.Ints(cells)
+	for _, cell := range cells {
+		senders := incoming[cell]
+		if staying[cell]+len(senders) <= CellCapacity(machines[cell].Machine) {
+			continue
+		}
+		blocked[senders[len(senders)-1]] = true
+		return true
+	}
+	return false
+}
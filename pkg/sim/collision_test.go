@@ -0,0 +1,185 @@
+package sim
+
+import (
+	"testing"
+)
+
+// cell returns the grid position of a floor cell.
+func cell(row, col int) int {
+	return row*GridCols + col
+}
+
+// consumedIDs returns the IDs of the objects consumed during a run, in order.
+func consumedIDs(changes [][]*Change) []int {
+	var ids []int
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				ids = append(ids, ch.StartObject.ID)
+			}
+		}
+	}
+	return ids
+}
+
+func TestCollisionHeadOn(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationWest}
+	machines[cell(1, 4)] = &MachineState{Machine: &Miner{}, Orientation: OrientationWest}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	// Each miner drops one object onto its conveyor, the conveyors push them
+	// into each other and both stay put, and the miners' later objects have
+	// nowhere to go.
+	last := changes[len(changes)-1]
+	if len(last) != 2 {
+		t.Fatalf("Expected two objects left at the end, got %d", len(last))
+	}
+	for _, ch := range last {
+		if !ch.Blocked || ch.Fate != FateWaiting {
+			t.Errorf("Expected the last tick to leave blocked objects waiting, got blocked=%v fate=%s", ch.Blocked, FateName(ch.Fate))
+		}
+		if ch.EndObject.GridPosition != ch.StartObject.GridPosition {
+			t.Errorf("Expected object %d to stay put, it moved from %d to %d", ch.StartObject.ID, ch.StartObject.GridPosition, ch.EndObject.GridPosition)
+		}
+	}
+	for _, tickChanges := range changes[1:] {
+		for _, ch := range tickChanges {
+			if ch.EndObject != nil && ch.StartObject.GridPosition != ch.EndObject.GridPosition {
+				t.Errorf("Expected no object to move after the first tick, object %d moved from %d to %d", ch.StartObject.ID, ch.StartObject.GridPosition, ch.EndObject.GridPosition)
+			}
+		}
+	}
+}
+
+func TestCollisionMergePriority(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Two conveyors feed a shared conveyor from above and below. The one
+	// above comes first in grid order, so it wins every conflict and the one
+	// below waits until the upper line runs dry.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationSouth}
+	machines[cell(3, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(3, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+	machines[cell(2, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(2, 3)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	// Tick 0 mines upper object 1 and lower object 2. The lower line stays
+	// blocked behind object 2, so its miner's later objects are never made.
+	got := consumedIDs(changes)
+	want := []int{1, 3, 4, 2}
+	if len(got) != len(want) {
+		t.Fatalf("Expected consumed objects %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected consumed objects %v, got %v", want, got)
+		}
+	}
+}
+
+func TestCollisionMergeIntoCombiner(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Two conveyors feed a combiner at the same time; its cell holds two, so
+	// both arrive and are crafted into purple.
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 3)}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationSouth}
+	machines[cell(3, 1)] = &MachineState{Machine: &source{types: repeat(t, "blue", 3)}, Orientation: OrientationEast}
+	machines[cell(3, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+	machines[cell(2, 2)] = &MachineState{Machine: &Combiner{}, Orientation: OrientationEast}
+	machines[cell(2, 3)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	got := consumedIDs(changes)
+	if len(got) != 3 {
+		t.Fatalf("Expected three combined objects to be consumed, got %v", got)
+	}
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject != nil && ch.EndObject.ID != ch.StartObject.ID && ch.Recipe != "purple" {
+				t.Errorf("Expected object %d to be crafted by the purple recipe, got %q", ch.EndObject.ID, ch.Recipe)
+			}
+			if ch.Blocked {
+				t.Errorf("Expected nothing to be blocked, object %d was", ch.StartObject.ID)
+			}
+		}
+	}
+}
+
+func TestCollisionConveyorChain(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	for col := 2; col <= 5; col++ {
+		machines[cell(1, col)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	}
+	machines[cell(1, 6)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	// Every object moves into the cell the one ahead of it is leaving, so the
+	// line advances one cell per tick without gaps or stalls.
+	if got := consumedIDs(changes); len(got) != 3 {
+		t.Fatalf("Expected all three objects to be consumed, got %v", got)
+	}
+	if len(changes) != 8 {
+		t.Errorf("Expected the line to drain in 8 ticks, took %d", len(changes))
+	}
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.Blocked {
+				t.Errorf("Expected nothing to be blocked, object %d was", ch.StartObject.ID)
+			}
+		}
+	}
+}
+
+func TestCollisionChainBacksUp(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// A line of conveyors whose last conveyor points back into the line, so it
+	// refuses objects coming in at its front. Once the front object is stuck,
+	// every object behind it stalls too.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 4)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationWest}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("Expected the line to fill in 2 ticks and stall on the 3rd, took %d", len(changes))
+	}
+	waiting := make(map[int]int)
+	for _, ch := range changes[len(changes)-1] {
+		if !ch.Blocked || ch.Fate != FateWaiting {
+			t.Errorf("Expected object %d to be left blocked and waiting", ch.StartObject.ID)
+		}
+		waiting[ch.EndObject.GridPosition] = ch.EndObject.ID
+	}
+	want := map[int]int{cell(1, 2): 2, cell(1, 3): 1}
+	for pos, id := range want {
+		if waiting[pos] != id {
+			t.Errorf("Expected object %d waiting at %d, got %d", id, pos, waiting[pos])
+		}
+	}
+}
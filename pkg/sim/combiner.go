@@ -0,0 +1,93 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Combiner represents a combiner machine.
+type Combiner struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "combiner",
+		Name:         "Combiner",
+		Description:  "Crafts two objects fed in from its sides into one by recipe, such as red and blue into purple. Pairs with no recipe pass through.",
+		Roles:        []MachineRole{RoleConsumer, RoleProducer},
+		Color:        color.RGBA{R: 255, G: 0, B: 255, A: 255}, // Magenta
+		Rarity:       RarityUncommon,
+		Cost:         4,
+		DefaultCount: 1,
+		Machine:      &Combiner{},
+	})
+}
+
+// New returns a fresh combiner for a new placement.
+func (c *Combiner) New() MachineInterface {
+	return &Combiner{}
+}
+
+// GetType returns the machine type.
+func (c *Combiner) GetType() MachineType {
+	return MachineCombiner
+}
+
+// GetCapacity returns how many objects the combiner can hold while it waits
+// for a pair.
+func (c *Combiner) GetCapacity() int {
+	return 2
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (c *Combiner) GetInputs() []Side {
+	return []Side{SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (c *Combiner) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for combiner. Once two objects are
+// inside, it crafts them by recipe into one object out of its front. A pair
+// with no recipe is not combined: the first object passes through as it is
+// and the second waits for a new partner.
+func (c *Combiner) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	var objectsAtPos []*Object
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			objectsAtPos = append(objectsAtPos, obj)
+		}
+	}
+	if len(objectsAtPos) < 2 {
+		return nil
+	}
+	pair := objectsAtPos[:2]
+	nextPos := GetAdjacentPosition(position, orientation)
+	recipe, ok := FindRecipe(pair)
+	if !ok {
+		obj := pair[0]
+		return []*Change{{
+			StartObject: obj,
+			EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+			Score:       nil,
+		}}
+	}
+	return []*Change{{
+		StartObject: pair[0],
+		EndObject:   recipe.Craft(pair, nextPos),
+		Score:       nil,
+		Recipe:      recipe.Key,
+	}, {
+		StartObject: pair[1],
+		EndObject:   nil, // Remove the second object
+		Score:       nil,
+	}}
+}
+
+// EmitEffects emits effects from combiner.
+func (c *Combiner) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// No effects for now
+	return nil
+}
@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Conveyor represents a conveyor machine.
+type Conveyor struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "conveyor",
+		Name:         "Conveyor",
+		Description:  "Moves objects in the direction it's facing.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 200, G: 200, B: 200, A: 255},
+		Rarity:       RarityCommon,
+		Cost:         1,
+		DefaultCount: 5,
+		Machine:      &Conveyor{},
+	})
+}
+
+// New returns a fresh conveyor for a new placement.
+func (c *Conveyor) New() MachineInterface {
+	return &Conveyor{}
+}
+
+// GetType returns the machine type.
+func (c *Conveyor) GetType() MachineType {
+	return MachineConveyor
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (c *Conveyor) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (c *Conveyor) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for conveyor.
+func (c *Conveyor) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from conveyor.
+func (c *Conveyor) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
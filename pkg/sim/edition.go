@@ -0,0 +1,78 @@
+package sim
+
+// Edition is a modifier an object carries through the factory, in the style
+// of a card edition. It stays with the object through moves and transforms,
+// is passed on to both halves of a split and to whatever an object is
+// combined into, and is scored when the object is consumed.
+type Edition int
+
+const (
+	EditionNone Edition = iota
+	// EditionFoil adds foilValue to the object's value.
+	EditionFoil
+	// EditionHolographic adds holographicMult to the object's multiplier.
+	EditionHolographic
+	// EditionPolychrome multiplies the object's multiplier by
+	// polychromeMult.
+	EditionPolychrome
+	// EditionNegative takes up no room in the cell it is in.
+	EditionNegative
+)
+
+const (
+	foilValue       = 5
+	holographicMult = 3
+	polychromeMult  = 2
+)
+
+// Editions lists every edition an object can be given, leaving out
+// EditionNone.
+var Editions = []Edition{EditionFoil, EditionHolographic, EditionPolychrome, EditionNegative}
+
+// EditionName returns the display name of an edition.
+func EditionName(e Edition) string {
+	switch e {
+	case EditionFoil:
+		return "Foil"
+	case EditionHolographic:
+		return "Holographic"
+	case EditionPolychrome:
+		return "Polychrome"
+	case EditionNegative:
+		return "Negative"
+	default:
+		return "Base"
+	}
+}
+
+// FinalScore returns what the object scores when it is consumed: its score
+// with its edition applied.
+func (o *Object) FinalScore() *Score {
+	score := &Score{Value: o.Score.Value, MultAdd: o.Score.MultAdd, MultMult: o.Score.MultMult}
+	switch o.Edition {
+	case EditionFoil:
+		score.Value = saturatingAdd(score.Value, foilValue)
+	case EditionHolographic:
+		score.MultAdd = saturatingAdd(score.MultAdd, holographicMult)
+	case EditionPolychrome:
+		score.MultMult = saturatingMul(score.MultMult, polychromeMult)
+	}
+	return score
+}
+
+// takesRoom reports whether the object counts towards its cell's capacity.
+func (o *Object) takesRoom() bool {
+	return o.Edition != EditionNegative
+}
+
+// combinedEdition returns the edition an object made from others carries: the
+// highest of its parts' editions, in the order they are declared.
+func combinedEdition(parts []*Object) Edition {
+	edition := EditionNone
+	for _, obj := range parts {
+		if obj.Edition > edition {
+			edition = obj.Edition
+		}
+	}
+	return edition
+}
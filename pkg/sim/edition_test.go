@@ -0,0 +1,145 @@
+package sim
+
+import "testing"
+
+func TestFinalScore(t *testing.T) {
+	tests := []struct {
+		edition Edition
+		want    Score
+	}{
+		{EditionNone, Score{Value: 2, MultAdd: 1, MultMult: 3}},
+		{EditionFoil, Score{Value: 2 + foilValue, MultAdd: 1, MultMult: 3}},
+		{EditionHolographic, Score{Value: 2, MultAdd: 1 + holographicMult, MultMult: 3}},
+		{EditionPolychrome, Score{Value: 2, MultAdd: 1, MultMult: 3 * polychromeMult}},
+		{EditionNegative, Score{Value: 2, MultAdd: 1, MultMult: 3}},
+	}
+	for _, tt := range tests {
+		obj := &Object{Edition: tt.edition, Score: &Score{Value: 2, MultAdd: 1, MultMult: 3}}
+		if got := *obj.FinalScore(); got != tt.want {
+			t.Errorf("%s: Expected %+v, got %+v", EditionName(tt.edition), tt.want, got)
+		}
+		if obj.Score.Value != 2 || obj.Score.MultAdd != 1 || obj.Score.MultMult != 3 {
+			t.Errorf("%s: Expected the object's own score to be left alone, got %+v", EditionName(tt.edition), *obj.Score)
+		}
+	}
+}
+
+func TestEditionScoredOnConsumption(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1), edition: EditionFoil}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	var scores []*Score
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				scores = append(scores, ch.Score)
+			}
+		}
+	}
+	if len(scores) != 1 || scores[0].Value != 1+foilValue {
+		t.Errorf("Expected one foil red scoring %d, got %v", 1+foilValue, scores)
+	}
+}
+
+func TestEditionCarriedThroughSplitsAndCombines(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// The same layout as TestSimulateRunLineage, fed polychrome reds.
+	machines[cell(2, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 3), edition: EditionPolychrome}, Orientation: OrientationEast}
+	machines[cell(2, 2)] = &MachineState{Machine: &Splitter{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Processor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Processor{}, Orientation: OrientationSouth}
+	machines[cell(3, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(3, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+	machines[cell(2, 3)] = &MachineState{Machine: &Combiner{}, Orientation: OrientationEast}
+	machines[cell(2, 4)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	crafted := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject != nil && ch.EndObject.Edition != EditionPolychrome {
+				t.Errorf("Expected object %d to stay polychrome, got %s", ch.EndObject.ID, EditionName(ch.EndObject.Edition))
+			}
+			if ch.Recipe != "" {
+				crafted++
+			}
+		}
+	}
+	if crafted != 3 {
+		t.Errorf("Expected 3 objects to be crafted, got %d", crafted)
+	}
+}
+
+func TestNegativeTakesNoRoom(t *testing.T) {
+	tests := []struct {
+		edition     Edition
+		wantBlocked bool
+	}{
+		{EditionNone, true},
+		{EditionNegative, false},
+	}
+	for _, tt := range tests {
+		machines := make([]*MachineState, GridCols*GridRows)
+		// Two sources feed the same conveyor from its sides at once.
+		machines[cell(1, 2)] = &MachineState{Machine: &source{types: repeat(t, "red", 1), edition: tt.edition}, Orientation: OrientationSouth}
+		machines[cell(3, 2)] = &MachineState{Machine: &source{types: repeat(t, "red", 1), edition: tt.edition}, Orientation: OrientationNorth}
+		machines[cell(2, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+		machines[cell(2, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+
+		changes, err := SimulateRun(machines, 1)
+		if err != nil {
+			t.Fatalf("SimulateRun failed: %v", err)
+		}
+		blocked := false
+		for _, ch := range changes[0] {
+			if ch.Blocked {
+				blocked = true
+			}
+		}
+		if len(changes[0]) != 2 {
+			blocked = true
+		}
+		if blocked != tt.wantBlocked {
+			t.Errorf("%s: Expected blocked %v on the first tick, got %v", EditionName(tt.edition), tt.wantBlocked, blocked)
+		}
+	}
+}
+
+func TestPainterGivesEditions(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Painter{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 4)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	consumed := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				consumed++
+				if ch.StartObject.Edition == EditionNone {
+					t.Errorf("Expected object %d to be painted", ch.StartObject.ID)
+				}
+			}
+		}
+	}
+	if consumed != 3 {
+		t.Errorf("Expected 3 painted objects to be consumed, got %d", consumed)
+	}
+}
@@ -0,0 +1,202 @@
+package sim
+
+// EffectType represents different effects machines can have.
+type EffectType int
+
+const (
+	EffectHolographic EffectType = iota
+	EffectShiny
+	EffectBuffSpeed
+	EffectAmplifyValue
+	EffectBuffEfficiency
+	EffectRetrigger
+)
+
+// DurationType represents how effect duration is measured.
+type DurationType int
+
+const (
+	DurationTick DurationType = iota
+	DurationRun
+	DurationRound
+)
+
+// EffectInterface defines the behavior of effects.
+type EffectInterface interface {
+	GetType() EffectType
+	GetDurationType() DurationType
+	Apply(change *Change) bool
+	Update(state *MachineState, elapsed DurationType)
+	IsExpired() bool
+	Clone() EffectInterface
+}
+
+// Effect represents an effect applied to a machine.
+//
+// Effects change the output of the machine they are attached to:
+//   - EffectBuffSpeed lets the machine handle a second object on its cell each tick.
+//   - EffectBuffEfficiency adds 1 to the value of everything the machine outputs.
+//   - EffectAmplifyValue doubles the value of everything the machine outputs.
+//   - EffectHolographic adds 1 to the additive multiplier of the machine's output.
+//   - EffectShiny doubles the multiplicative multiplier of the machine's output.
+//   - EffectRetrigger triggers the machine once more on everything it does
+//     (see retriggerMachine). Retriggers stack.
+type Effect struct {
+	Type         EffectType
+	Duration     int
+	DurationType DurationType
+}
+
+// GetType returns the effect type.
+func (e *Effect) GetType() EffectType {
+	return e.Type
+}
+
+// GetDurationType returns how the effect's duration is measured.
+func (e *Effect) GetDurationType() DurationType {
+	return e.DurationType
+}
+
+// Apply modifies a change produced by the machine the effect is attached to.
+// It reports whether the change was modified.
+func (e *Effect) Apply(change *Change) bool {
+	switch e.Type {
+	case EffectBuffEfficiency, EffectAmplifyValue, EffectHolographic, EffectShiny:
+		score := detachScore(change)
+		if score == nil {
+			return false
+		}
+		switch e.Type {
+		case EffectBuffEfficiency:
+			score.Value = saturatingAdd(score.Value, 1)
+		case EffectAmplifyValue:
+			score.Value = saturatingMul(score.Value, 2)
+		case EffectHolographic:
+			score.MultAdd = saturatingAdd(score.MultAdd, 1)
+		case EffectShiny:
+			score.MultMult = saturatingMul(score.MultMult, 2)
+		}
+		return true
+	}
+	// Speed is handled by the simulator when it decides how many objects the
+	// machine may process, and retriggers once the machine has been processed.
+	return false
+}
+
+// Update advances the effect once a tick, run or round has elapsed. Only the
+// unit the effect's duration is measured in counts down.
+func (e *Effect) Update(state *MachineState, elapsed DurationType) {
+	if e.DurationType == elapsed {
+		e.Duration--
+	}
+}
+
+// IsExpired checks if the effect has expired.
+func (e *Effect) IsExpired() bool {
+	return e.Duration <= 0
+}
+
+// Clone returns a copy of the effect that counts down independently.
+func (e *Effect) Clone() EffectInterface {
+	clone := *e
+	return &clone
+}
+
+// EffectEmission represents an effect emitted by a machine to other machines.
+type EffectEmission struct {
+	TargetGridX int
+	TargetGridY int
+	Effect      EffectInterface
+}
+
+// detachScore returns the score a change carries forward, copied so an effect
+// can modify it without touching the score of the object it came from.
+func detachScore(change *Change) *Score {
+	if change.EndObject != nil && change.EndObject.Score != nil {
+		score := *change.EndObject.Score
+		change.EndObject.Score = &score
+		return &score
+	}
+	if change.Score != nil {
+		score := *change.Score
+		change.Score = &score
+		return &score
+	}
+	return nil
+}
+
+// hasEffect reports whether a machine currently has an effect of the given type.
+func (ms *MachineState) hasEffect(effectType EffectType) bool {
+	for _, e := range ms.Effects {
+		if e.GetType() == effectType {
+			return true
+		}
+	}
+	return false
+}
+
+// Upgrades returns how many of a machine's effects are upgrades: effects that
+// last the rest of the round or longer, such as an Engraver's.
+func (ms *MachineState) Upgrades() int {
+	upgrades := 0
+	for _, e := range ms.Effects {
+		if e.GetDurationType() >= DurationRound {
+			upgrades++
+		}
+	}
+	return upgrades
+}
+
+// countEffect returns how many effects of the given type a machine has.
+func (ms *MachineState) countEffect(effectType EffectType) int {
+	count := 0
+	for _, e := range ms.Effects {
+		if e.GetType() == effectType {
+			count++
+		}
+	}
+	return count
+}
+
+// addEffect attaches an effect to a machine. An effect of the same type that is
+// already attached is replaced, so a machine that is re-emitted to every tick
+// has its effect refreshed rather than stacked. Retriggers are the exception:
+// each one emitted onto a machine triggers it again.
+func (ms *MachineState) addEffect(effect EffectInterface) {
+	if effect.GetType() == EffectRetrigger {
+		ms.Effects = append(ms.Effects, effect)
+		return
+	}
+	for i, e := range ms.Effects {
+		if e.GetType() == effect.GetType() {
+			ms.Effects[i] = effect
+			return
+		}
+	}
+	ms.Effects = append(ms.Effects, effect)
+}
+
+// expireEffects advances every effect on the machine once a tick, run or round
+// has elapsed and drops those that have run out. Tick effects never outlive
+// the run they were emitted in, and run effects never outlive the round.
+func (ms *MachineState) expireEffects(elapsed DurationType) {
+	remaining := ms.Effects[:0]
+	for _, e := range ms.Effects {
+		e.Update(ms, elapsed)
+		if e.IsExpired() || e.GetDurationType() < elapsed {
+			continue
+		}
+		remaining = append(remaining, e)
+	}
+	ms.Effects = remaining
+}
+
+// EndRound expires the effects on machines that last until the end of the
+// round. Tick and run effects are already gone by the end of every run.
+func EndRound(machines []*MachineState) {
+	for pos, ms := range machines {
+		if ms != nil && isAnchor(machines, pos) {
+			ms.expireEffects(DurationRound)
+		}
+	}
+}
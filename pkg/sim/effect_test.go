@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEffectApply(t *testing.T) {
+	tests := []struct {
+		effect EffectType
+		want   Score
+	}{
+		{EffectBuffEfficiency, Score{Value: 3, MultAdd: 1, MultMult: 1}},
+		{EffectAmplifyValue, Score{Value: 4, MultAdd: 1, MultMult: 1}},
+		{EffectHolographic, Score{Value: 2, MultAdd: 2, MultMult: 1}},
+		{EffectShiny, Score{Value: 2, MultAdd: 1, MultMult: 2}},
+	}
+	for _, tt := range tests {
+		obj := &Object{ID: 1, GridPosition: cell(1, 1), Score: &Score{Value: 2, MultAdd: 1, MultMult: 1}}
+		ms := &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+		ms.addEffect(&Effect{Type: tt.effect, Duration: 1, DurationType: DurationTick})
+
+		changes := processMachine(cell(1, 1), ms, [][]*Object{{obj}}, 0, rand.New(rand.NewSource(1)), Memory{})
+		if len(changes) != 1 {
+			t.Fatalf("Effect %d: Expected one change, got %d", tt.effect, len(changes))
+		}
+		if got := *changes[0].EndObject.Score; got != tt.want {
+			t.Errorf("Effect %d: Expected %+v, got %+v", tt.effect, tt.want, got)
+		}
+		if len(changes[0].Effects) != 1 || changes[0].Effects[0] != tt.effect {
+			t.Errorf("Effect %d: Expected the change to record the effect, got %v", tt.effect, changes[0].Effects)
+		}
+		if obj.Score.Value != 2 || obj.Score.MultAdd != 1 || obj.Score.MultMult != 1 {
+			t.Errorf("Effect %d: Expected the start object's score to be left alone, got %+v", tt.effect, *obj.Score)
+		}
+	}
+}
+
+func TestBuffSpeedSecondPass(t *testing.T) {
+	tests := []struct {
+		name    string
+		machine MachineInterface
+		buffed  bool
+		want    int
+	}{
+		{"unbuffed conveyor", &Conveyor{}, false, 1},
+		{"buffed conveyor", &Conveyor{}, true, 2},
+		// Producers take nothing from the floor, so they get no second pass.
+		{"buffed producer", &source{types: repeat(t, "red", 1)}, true, 1},
+	}
+	for _, tt := range tests {
+		objects := []*Object{
+			{ID: 1, GridPosition: cell(1, 1), Score: &Score{Value: 1, MultMult: 1}},
+			{ID: 2, GridPosition: cell(1, 1), Score: &Score{Value: 1, MultMult: 1}},
+		}
+		ms := &MachineState{Machine: tt.machine, Orientation: OrientationEast}
+		if tt.buffed {
+			ms.addEffect(&Effect{Type: EffectBuffSpeed, Duration: 1, DurationType: DurationTick})
+		}
+
+		changes := processMachine(cell(1, 1), ms, [][]*Object{objects}, 0, rand.New(rand.NewSource(1)), Memory{})
+		if len(changes) != tt.want {
+			t.Fatalf("%s: Expected %d changes, got %d", tt.name, tt.want, len(changes))
+		}
+		if tt.want == 2 {
+			if changes[0].StartObject == changes[1].StartObject {
+				t.Errorf("%s: Expected the second pass to take the other object", tt.name)
+			}
+			if len(changes[1].Effects) != 1 || changes[1].Effects[0] != EffectBuffSpeed {
+				t.Errorf("%s: Expected the second pass to record EffectBuffSpeed, got %v", tt.name, changes[1].Effects)
+			}
+		}
+	}
+}
+
+func TestEffectExpiry(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	ms := &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 1)] = ms
+	ms.addEffect(&Effect{Type: EffectBuffSpeed, Duration: 5, DurationType: DurationTick})
+	ms.addEffect(&Effect{Type: EffectAmplifyValue, Duration: 2, DurationType: DurationRun})
+	ms.addEffect(&Effect{Type: EffectShiny, Duration: 1, DurationType: DurationRound})
+
+	for run, want := range [][]EffectType{
+		{EffectAmplifyValue, EffectShiny},
+		{EffectShiny},
+	} {
+		if _, err := SimulateRun(machines, 1); err != nil {
+			t.Fatalf("SimulateRun failed: %v", err)
+		}
+		if len(ms.Effects) != len(want) {
+			t.Fatalf("Run %d: Expected effects %v, got %d effects", run+1, want, len(ms.Effects))
+		}
+		for i, e := range ms.Effects {
+			if e.GetType() != want[i] {
+				t.Errorf("Run %d: Expected effect %d to be %d, got %d", run+1, i, want[i], e.GetType())
+			}
+		}
+	}
+
+	EndRound(machines)
+	if len(ms.Effects) != 0 {
+		t.Errorf("Expected round effects to expire at the end of the round, got %d", len(ms.Effects))
+	}
+}
+
+func TestUpgradesLastTheRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		upgrader MachineInterface
+		effect   EffectType
+		want     Score
+	}{
+		{"engraver", &Engraver{}, EffectHolographic, Score{Value: 1, MultAdd: 1, MultMult: 1}},
+		{"polisher", &Polisher{}, EffectShiny, Score{Value: 1, MultMult: 2}},
+	}
+	for _, tt := range tests {
+		machines := make([]*MachineState, GridCols*GridRows)
+		machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1)}, Orientation: OrientationEast}
+		conveyor := &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+		machines[cell(1, 2)] = conveyor
+		machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+		machines[cell(2, 2)] = &MachineState{Machine: tt.upgrader, Orientation: OrientationEast}
+
+		changes, err := SimulateRun(machines, 1)
+		if err != nil {
+			t.Fatalf("%s: SimulateRun failed: %v", tt.name, err)
+		}
+		var scores []Score
+		for _, tickChanges := range changes {
+			for _, ch := range tickChanges {
+				if Consumed(ch) {
+					scores = append(scores, *ch.Score)
+				}
+			}
+		}
+		if len(scores) != 1 || scores[0] != tt.want {
+			t.Errorf("%s: Expected one red scoring %+v, got %+v", tt.name, tt.want, scores)
+		}
+		if !conveyor.hasEffect(tt.effect) || conveyor.Upgrades() != 1 {
+			t.Errorf("%s: Expected the upgrade to outlast the run", tt.name)
+		}
+		EndRound(machines)
+		if conveyor.hasEffect(tt.effect) || conveyor.Upgrades() != 0 {
+			t.Errorf("%s: Expected the upgrade to expire at the end of the round", tt.name)
+		}
+	}
+}
+
+func TestUpgrades(t *testing.T) {
+	ms := &MachineState{Machine: &Conveyor{}}
+	ms.addEffect(&Effect{Type: EffectBuffSpeed, Duration: 5, DurationType: DurationTick})
+	ms.addEffect(&Effect{Type: EffectAmplifyValue, Duration: 1, DurationType: DurationRun})
+	if got := ms.Upgrades(); got != 0 {
+		t.Errorf("Expected tick and run effects not to count as upgrades, got %d", got)
+	}
+	ms.addEffect(&Effect{Type: EffectHolographic, Duration: 1, DurationType: DurationRound})
+	ms.addEffect(&Effect{Type: EffectShiny, Duration: 1, DurationType: DurationRound})
+	if got := ms.Upgrades(); got != 2 {
+		t.Errorf("Expected 2 upgrades, got %d", got)
+	}
+}
@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// GeneralConsumer represents a general consumer machine.
+type GeneralConsumer struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "consumer",
+		Name:         "General Consumer",
+		Description:  "Collects objects that reach it, scoring points based on their color and paying $1 for each.",
+		Roles:        []MachineRole{RoleConsumer},
+		Color:        color.RGBA{R: 255, G: 150, B: 150, A: 255},
+		Rarity:       RarityCommon,
+		Cost:         3,
+		DefaultCount: 1,
+		Machine:      &GeneralConsumer{},
+	})
+}
+
+// New returns a fresh general consumer for a new placement.
+func (e *GeneralConsumer) New() MachineInterface {
+	return &GeneralConsumer{}
+}
+
+// GetType returns the machine type.
+func (e *GeneralConsumer) GetType() MachineType {
+	return MachineGeneralConsumer
+}
+
+// GetCapacity returns how many objects the consumer can take in at once, one
+// from each side.
+func (e *GeneralConsumer) GetCapacity() int {
+	return 4
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (e *GeneralConsumer) GetInputs() []Side {
+	return []Side{SideFront, SideRight, SideBack, SideLeft}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (e *GeneralConsumer) GetOutputs() []Side {
+	return nil
+}
+
+// Process handles object interaction for general consumer.
+func (e *GeneralConsumer) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	var changes []*Change
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			changes = append(changes, &Change{
+				StartObject: obj,
+				EndObject:   nil,
+				Score:       obj.FinalScore(),
+				Payout:      1,
+			})
+		}
+	}
+	return changes
+}
+
+// Retrigger scores the object the consumer took once more. Only the first
+// scoring pays out.
+func (e *GeneralConsumer) Retrigger(change *Change) *Change {
+	if change.EndObject != nil || change.Score == nil {
+		return nil
+	}
+	score := *change.Score
+	return &Change{StartObject: change.StartObject, Score: &score}
+}
+
+// EmitEffects emits effects from general consumer.
+func (e *GeneralConsumer) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
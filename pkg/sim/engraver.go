@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Engraver represents an engraver machine.
+type Engraver struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "engraver",
+		Name:         "Engraver",
+		Description:  "Moves objects forward and engraves adjacent machines for the rest of the round, giving everything they output +1 mult.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 180, G: 180, B: 200, A: 255}, // Silver
+		Rarity:       RarityRare,
+		Cost:         8,
+		DefaultCount: 1,
+		Machine:      &Engraver{},
+	})
+}
+
+// New returns a fresh engraver for a new placement.
+func (e *Engraver) New() MachineInterface {
+	return &Engraver{}
+}
+
+// GetType returns the machine type.
+func (e *Engraver) GetType() MachineType {
+	return MachineEngraver
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (e *Engraver) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (e *Engraver) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for engraver.
+func (e *Engraver) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from engraver.
+func (e *Engraver) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Upgrade adjacent machines for the rest of the round
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				emissions = append(emissions, EffectEmission{
+					TargetGridX: nc,
+					TargetGridY: nr,
+					Effect: &Effect{
+						Type:         EffectHolographic,
+						Duration:     1,
+						DurationType: DurationRound,
+					},
+				})
+			}
+		}
+	}
+	return emissions
+}
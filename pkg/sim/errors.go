@@ -0,0 +1,54 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInfiniteLoop is returned when the factory returns to a state it has
+	// already been in, or is still running when the tick limit is reached.
+	ErrInfiniteLoop = errors.New("infinite loop")
+	// ErrObjectExplosion is returned when the number of objects on the floor
+	// grows past the object limit.
+	ErrObjectExplosion = errors.New("object explosion")
+)
+
+// RunError describes why SimulateRun stopped early. Err is one of the
+// sentinel errors above, Tick is the tick the problem was detected on and
+// Cells are the grid positions of the objects involved.
+type RunError struct {
+	Err   error
+	Tick  int
+	Cells []int
+}
+
+// Error implements error.
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%v at tick %d involving %d cells", e.Err, e.Tick, len(e.Cells))
+}
+
+// Unwrap lets errors.Is match the sentinel error.
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// ScriptError describes a mod script that failed on an object during a run.
+// The object stays where it is that tick and the run carries on. Err is what
+// the script failed with, such as ErrStepLimit, Tick is the tick it failed on
+// and Position is the grid position of the machine running it.
+type ScriptError struct {
+	Err      error
+	Tick     int
+	Position int
+}
+
+// Error implements error.
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script at cell %d failed at tick %d: %v", e.Position, e.Tick, e.Err)
+}
+
+// Unwrap lets errors.Is match what the script failed with.
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,113 @@
+package sim
+
+// footprintMachine is implemented by machines that cover more than one cell.
+type footprintMachine interface {
+	GetFootprint() (width, depth int)
+}
+
+// Footprint returns how many columns and rows a machine covers when it faces
+// orientation. A machine is width cells across its front and depth cells from
+// back to front, so turning it east or west swaps the two.
+func Footprint(machine MachineInterface, orientation Orientation) (cols, rows int) {
+	width, depth := 1, 1
+	if m, ok := machine.(footprintMachine); ok {
+		width, depth = m.GetFootprint()
+	}
+	if orientation == OrientationEast || orientation == OrientationWest {
+		return depth, width
+	}
+	return width, depth
+}
+
+// CoveredCells returns the grid positions a machine covers when its top-left
+// cell is anchor, in grid order. It returns nil if any of them would be off
+// the grid.
+func CoveredCells(anchor int, machine MachineInterface, orientation Orientation) []int {
+	if anchor < 0 || anchor >= GridCols*GridRows {
+		return nil
+	}
+	cols, rows := Footprint(machine, orientation)
+	row, col := anchor/GridCols, anchor%GridCols
+	if row+rows > GridRows || col+cols > GridCols {
+		return nil
+	}
+	cells := make([]int, 0, cols*rows)
+	for r := row; r < row+rows; r++ {
+		for c := col; c < col+cols; c++ {
+			cells = append(cells, r*GridCols+c)
+		}
+	}
+	return cells
+}
+
+// Place puts a machine on the grid with its top-left cell at anchor. Every
+// cell a machine covers holds the same *MachineState. Place reports false,
+// changing nothing, if any of those cells is off the grid or already taken.
+func Place(machines []*MachineState, anchor int, ms *MachineState) bool {
+	cells := CoveredCells(anchor, ms.Machine, ms.Orientation)
+	if cells == nil {
+		return false
+	}
+	for _, pos := range cells {
+		if pos >= len(machines) || machines[pos] != nil {
+			return false
+		}
+	}
+	for _, pos := range cells {
+		machines[pos] = ms
+	}
+	return true
+}
+
+// Anchor returns the top-left cell of the machine covering pos. A machine is
+// processed once per tick, at its anchor, however many cells it covers.
+func Anchor(machines []*MachineState, pos int) int {
+	if pos < 0 || pos >= len(machines) || machines[pos] == nil {
+		return pos
+	}
+	ms := machines[pos]
+	for pos%GridCols > 0 && machines[pos-1] == ms {
+		pos--
+	}
+	for pos >= GridCols && machines[pos-GridCols] == ms {
+		pos -= GridCols
+	}
+	return pos
+}
+
+// isAnchor reports whether pos holds a machine and is its top-left cell.
+func isAnchor(machines []*MachineState, pos int) bool {
+	return machines[pos] != nil && Anchor(machines, pos) == pos
+}
+
+// ExitPositions returns the cells just outside one side of a machine's
+// footprint, in grid order, when its top-left cell is anchor. Exits off the
+// grid are OffGrid.
+func ExitPositions(anchor int, machine MachineInterface, orientation Orientation, side Side) []int {
+	cells := CoveredCells(anchor, machine, orientation)
+	covered := make(map[int]bool, len(cells))
+	for _, pos := range cells {
+		covered[pos] = true
+	}
+	var exits []int
+	for _, pos := range cells {
+		if next := GetAdjacentPosition(pos, side.Facing(orientation)); !covered[next] {
+			exits = append(exits, next)
+		}
+	}
+	return exits
+}
+
+// ObjectsOn returns the objects in objects that sit on any of cells.
+func ObjectsOn(objects []*Object, cells []int) []*Object {
+	var result []*Object
+	for _, obj := range objects {
+		for _, pos := range cells {
+			if obj.GridPosition == pos {
+				result = append(result, obj)
+				break
+			}
+		}
+	}
+	return result
+}
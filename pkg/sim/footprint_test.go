@@ -0,0 +1,110 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestFootprintRotates(t *testing.T) {
+	tests := []struct {
+		orientation Orientation
+		cols, rows  int
+	}{
+		{OrientationNorth, 2, 1},
+		{OrientationEast, 1, 2},
+		{OrientationSouth, 2, 1},
+		{OrientationWest, 1, 2},
+	}
+	for _, tt := range tests {
+		cols, rows := Footprint(&Smelter{}, tt.orientation)
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("Expected a smelter facing %d to cover %dx%d, got %dx%d", tt.orientation, tt.cols, tt.rows, cols, rows)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	assembler := &MachineState{Machine: &Assembler{}, Orientation: OrientationEast}
+	if !Place(machines, cell(2, 2), assembler) {
+		t.Fatal("Expected the assembler to fit on an empty grid")
+	}
+	for _, pos := range []int{cell(2, 2), cell(2, 3), cell(3, 2), cell(3, 3)} {
+		if machines[pos] != assembler {
+			t.Errorf("Expected the assembler to cover %d", pos)
+		}
+		if got := Anchor(machines, pos); got != cell(2, 2) {
+			t.Errorf("Expected %d to be anchored at %d, got %d", pos, cell(2, 2), got)
+		}
+	}
+
+	smelter := &MachineState{Machine: &Smelter{}, Orientation: OrientationNorth}
+	if Place(machines, cell(3, 1), smelter) {
+		t.Error("Expected a smelter overlapping the assembler not to be placed")
+	}
+	if machines[cell(3, 1)] != nil {
+		t.Error("Expected a failed placement to leave the grid unchanged")
+	}
+	if Place(machines, cell(1, GridCols-1), smelter) {
+		t.Error("Expected a smelter hanging off the grid not to be placed")
+	}
+}
+
+func TestSimulateRunSmelterTakesEitherCell(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// A smelter facing east covers two rows, each fed by its own miner.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(2, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	Place(machines, cell(1, 2), &MachineState{Machine: &Smelter{}, Orientation: OrientationEast})
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	consumed := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				consumed++
+				// The smelter runs once per tick however many cells it has.
+				if ch.Score.Value != 3 {
+					t.Errorf("Expected object %d to be smelted once to value 3, got %d", ch.StartObject.ID, ch.Score.Value)
+				}
+			}
+		}
+	}
+	if consumed != 6 {
+		t.Errorf("Expected all 6 objects to pass through the smelter, got %d", consumed)
+	}
+}
+
+func TestSimulateRunAssembler(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "green", 3)}, Orientation: OrientationEast}
+	Place(machines, cell(1, 2), &MachineState{Machine: &Assembler{}, Orientation: OrientationEast})
+	machines[cell(1, 4)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	var scores []*Score
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				scores = append(scores, ch.Score)
+			}
+		}
+	}
+	if len(scores) != 1 {
+		t.Fatalf("Expected one assembled object, got %d", len(scores))
+	}
+	// Three greens make an emerald worth their value plus the recipe's
+	// bonus of 5 value, +2 mult and x2 mult.
+	if scores[0].Value != 8 || scores[0].MultAdd != 2 || scores[0].MultMult != 2 {
+		t.Errorf("Expected value 8, +2 mult and x2 mult, got value %d, +%d mult and x%d mult", scores[0].Value, scores[0].MultAdd, scores[0].MultMult)
+	}
+}
@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Foreman is a passive card the player holds that bends the rules of a run,
+// in the style of a joker. Foremen are evaluated in the order the player
+// holds them, so one that adds to a score before another that multiplies it
+// does better than the other way round.
+type Foreman interface {
+	// OnChange sees each change that goes ahead during a run, in tick order,
+	// along with the machine that made it, before its end object lands. It
+	// may change the change's end object or score, but must replace rather
+	// than edit a Score it changes, since scores are shared between an
+	// object's moves.
+	OnChange(tick int, machine MachineInterface, change *Change)
+	// OnRunEnd sees every change of a finished run and may change the run's
+	// total as the foremen before it left it.
+	OnRunEnd(changes [][]*Change, total *RunTotal)
+}
+
+// ForemanSpec describes a kind of foreman card. Key names it, Cost is its
+// shop price and New makes a card of the kind.
+type ForemanSpec struct {
+	Key         string
+	Name        string
+	Description string
+	Rarity      Rarity
+	Cost        int
+	New         func() Foreman
+}
+
+var foremenByKey = make(map[string]*ForemanSpec)
+
+// RegisterForeman adds a kind of foreman card to the registry. It panics if
+// the key is missing or already taken.
+func RegisterForeman(spec ForemanSpec) {
+	if spec.Key == "" || spec.New == nil {
+		panic(fmt.Errorf("foreman %q needs a key and a constructor", spec.Key))
+	}
+	if _, ok := foremenByKey[spec.Key]; ok {
+		panic(fmt.Errorf("foreman key %q is already registered", spec.Key))
+	}
+	foremenByKey[spec.Key] = &spec
+}
+
+// ForemanByKey returns the foreman spec registered under a key.
+func ForemanByKey(key string) (*ForemanSpec, bool) {
+	spec, ok := foremenByKey[key]
+	return spec, ok
+}
+
+// ForemanSpecs returns every registered foreman spec, sorted by key.
+func ForemanSpecs() []*ForemanSpec {
+	specs := make([]*ForemanSpec, 0, len(foremenByKey))
+	for _, spec := range foremenByKey {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
+	return specs
+}
+
+// EndRun lets each foreman change a finished run's total, in order. It is the
+// last phase of ScoreRun.
+func EndRun(foremen []Foreman, changes [][]*Change, total *RunTotal) {
+	for _, f := range foremen {
+		f.OnRunEnd(changes, total)
+	}
+}
+
+// Consumed reports whether a change is an object being consumed and scored.
+func Consumed(change *Change) bool {
+	return change.EndObject == nil && change.Score != nil
+}
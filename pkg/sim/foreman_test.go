@@ -0,0 +1,105 @@
+package sim
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestForemanOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		foremen []Foreman
+		want    int64
+	}{
+		{"add then multiply", []Foreman{&UnionRep{}, &SafetyOfficer{}}, (2 + 3) * 2},
+		{"multiply then add", []Foreman{&SafetyOfficer{}, &UnionRep{}}, 2*2 + 3},
+	}
+	for _, tt := range tests {
+		total := RunTotal{RoundScore: big.NewInt(10), Multiplier: big.NewInt(2)}
+		EndRun(tt.foremen, nil, &total)
+		if total.Multiplier.Int64() != tt.want {
+			t.Errorf("%s: Expected multiplier %d, got %d", tt.name, tt.want, total.Multiplier)
+		}
+	}
+}
+
+func TestForemenDuringRun(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Blues travel down a conveyor line long enough to still be moving on
+	// the fifth tick.
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "blue", 1)}, Orientation: OrientationEast}
+	for col := 2; col <= 6; col++ {
+		machines[cell(1, col)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	}
+	machines[cell(1, 7)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	tests := []struct {
+		name    string
+		foremen []Foreman
+		want    Score
+	}{
+		{"no foremen", nil, Score{Value: 1, MultMult: 1}},
+		{"blue collar", []Foreman{&BlueCollar{}}, Score{Value: 1, MultAdd: 2, MultMult: 1}},
+		{"overtime", []Foreman{&Overtime{}}, Score{Value: 2, MultMult: 1}},
+	}
+	for _, tt := range tests {
+		changes, err := SimulateRunWith(machines, 1, RunOptions{Foremen: tt.foremen})
+		if err != nil {
+			t.Fatalf("%s: SimulateRunWith failed: %v", tt.name, err)
+		}
+		var scores []Score
+		for _, tickChanges := range changes {
+			for _, ch := range tickChanges {
+				if Consumed(ch) {
+					scores = append(scores, *ch.Score)
+				}
+			}
+		}
+		if len(scores) != 1 || scores[0] != tt.want {
+			t.Errorf("%s: Expected one object scoring %+v, got %+v", tt.name, tt.want, scores)
+		}
+	}
+}
+
+func TestSafetyOfficer(t *testing.T) {
+	clean := [][]*Change{{{StartObject: &Object{}, Fate: FateInPlay}}}
+	spilled := [][]*Change{{{StartObject: &Object{}, Fate: FateSpilled}}}
+	total := RunTotal{RoundScore: big.NewInt(0), Multiplier: big.NewInt(3)}
+	(&SafetyOfficer{}).OnRunEnd(clean, &total)
+	if total.Multiplier.Int64() != 6 {
+		t.Errorf("Expected a clean run to double the multiplier to 6, got %d", total.Multiplier)
+	}
+	total = RunTotal{RoundScore: big.NewInt(0), Multiplier: big.NewInt(3)}
+	(&SafetyOfficer{}).OnRunEnd(spilled, &total)
+	if total.Multiplier.Int64() != 3 {
+		t.Errorf("Expected a run with a spill to leave the multiplier at 3, got %d", total.Multiplier)
+	}
+}
+
+func TestQuotaRetriggers(t *testing.T) {
+	tests := []struct {
+		name string
+		reds int
+		want int64
+	}{
+		{"short", quotaCount - 1, 0},
+		{"met", quotaCount, 10},
+	}
+	for _, tt := range tests {
+		machines := make([]*MachineState, GridCols*GridRows)
+		// The repeater retriggers the consumer, scoring every red twice.
+		machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", tt.reds)}, Orientation: OrientationEast}
+		machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+		machines[cell(2, 2)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+
+		changes, err := SimulateRun(machines, 1)
+		if err != nil {
+			t.Fatalf("%s: SimulateRun failed: %v", tt.name, err)
+		}
+		total := RunTotal{RoundScore: big.NewInt(0), Multiplier: big.NewInt(1)}
+		(&Quota{}).OnRunEnd(changes, &total)
+		if total.RoundScore.Int64() != tt.want {
+			t.Errorf("%s: Expected %d reds to add %d, got %d", tt.name, tt.reds, tt.want, total.RoundScore)
+		}
+	}
+}
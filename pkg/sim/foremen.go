@@ -0,0 +1,134 @@
+package sim
+
+import "math/big"
+
+// The foreman cards that come with the game.
+
+func init() {
+	RegisterForeman(ForemanSpec{
+		Key:         "blue_collar",
+		Name:        "Blue Collar",
+		Description: "+2 mult for every blue object consumed.",
+		Rarity:      RarityCommon,
+		Cost:        4,
+		New:         func() Foreman { return &BlueCollar{} },
+	})
+	RegisterForeman(ForemanSpec{
+		Key:         "overtime",
+		Name:        "Overtime",
+		Description: "Conveyors double the value of what they move on tick 5.",
+		Rarity:      RarityUncommon,
+		Cost:        5,
+		New:         func() Foreman { return &Overtime{} },
+	})
+	RegisterForeman(ForemanSpec{
+		Key:         "safety_officer",
+		Name:        "Safety Officer",
+		Description: "x2 mult if no object was spilled or lost.",
+		Rarity:      RarityRare,
+		Cost:        6,
+		New:         func() Foreman { return &SafetyOfficer{} },
+	})
+	RegisterForeman(ForemanSpec{
+		Key:         "quota",
+		Name:        "Quota",
+		Description: "+10 score if at least 5 objects were consumed.",
+		Rarity:      RarityCommon,
+		Cost:        4,
+		New:         func() Foreman { return &Quota{} },
+	})
+	RegisterForeman(ForemanSpec{
+		Key:         "union_rep",
+		Name:        "Union Rep",
+		Description: "+3 mult at the end of every run.",
+		Rarity:      RarityUncommon,
+		Cost:        5,
+		New:         func() Foreman { return &UnionRep{} },
+	})
+}
+
+// BlueCollar adds to the multiplier of every blue object consumed.
+type BlueCollar struct{}
+
+// OnChange adds +2 mult to blue objects as they are consumed.
+func (f *BlueCollar) OnChange(tick int, machine MachineInterface, change *Change) {
+	if Consumed(change) && change.StartObject.Type == ObjectBlue {
+		change.Score = &Score{Value: change.Score.Value, MultAdd: saturatingAdd(change.Score.MultAdd, 2), MultMult: change.Score.MultMult}
+	}
+}
+
+// OnRunEnd does nothing.
+func (f *BlueCollar) OnRunEnd(changes [][]*Change, total *RunTotal) {}
+
+// Overtime doubles the value of objects conveyors move on the fifth tick.
+type Overtime struct{}
+
+// overtimeTick is the tick, counting from zero, Overtime works on.
+const overtimeTick = 4
+
+// OnChange doubles the value of objects a conveyor moves on overtimeTick.
+func (f *Overtime) OnChange(tick int, machine MachineInterface, change *Change) {
+	if tick != overtimeTick || change.EndObject == nil || machine.GetType() != MachineConveyor {
+		return
+	}
+	s := change.EndObject.Score
+	change.EndObject.Score = &Score{Value: saturatingMul(s.Value, 2), MultAdd: s.MultAdd, MultMult: s.MultMult}
+}
+
+// OnRunEnd does nothing.
+func (f *Overtime) OnRunEnd(changes [][]*Change, total *RunTotal) {}
+
+// SafetyOfficer doubles the multiplier of a run that spilled and lost
+// nothing.
+type SafetyOfficer struct{}
+
+// OnChange does nothing.
+func (f *SafetyOfficer) OnChange(tick int, machine MachineInterface, change *Change) {}
+
+// OnRunEnd doubles the multiplier if no object was spilled or lost.
+func (f *SafetyOfficer) OnRunEnd(changes [][]*Change, total *RunTotal) {
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.Fate == FateSpilled || ch.Fate == FateLost {
+				return
+			}
+		}
+	}
+	total.Multiplier.Mul(total.Multiplier, big.NewInt(2))
+}
+
+// Quota rewards a run that consumed enough objects.
+type Quota struct{}
+
+// quotaCount is how many objects must be consumed to meet the quota.
+const quotaCount = 5
+
+// OnChange does nothing.
+func (f *Quota) OnChange(tick int, machine MachineInterface, change *Change) {}
+
+// OnRunEnd adds 10 to the round score if at least quotaCount objects were
+// consumed. An object a retrigger scores again still only counts once.
+func (f *Quota) OnRunEnd(changes [][]*Change, total *RunTotal) {
+	consumed := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if Consumed(ch) && ch.Retrigger == 0 {
+				consumed++
+			}
+		}
+	}
+	if consumed >= quotaCount {
+		total.RoundScore.Add(total.RoundScore, big.NewInt(10))
+	}
+}
+
+// UnionRep adds to the multiplier at the end of every run.
+type UnionRep struct{}
+
+// OnChange does nothing.
+func (f *UnionRep) OnChange(tick int, machine MachineInterface, change *Change) {}
+
+// OnRunEnd adds 3 to the multiplier.
+func (f *UnionRep) OnRunEnd(changes [][]*Change, total *RunTotal) {
+	total.Multiplier.Add(total.Multiplier, big.NewInt(3))
+}
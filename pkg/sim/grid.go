@@ -0,0 +1,102 @@
+package sim
+
+const (
+	// GridCols is the number of columns in the simulation grid, including the border.
+	GridCols = 9
+	// GridRows is the number of rows in the simulation grid, including the border.
+	GridRows = 9
+	// OffGrid is the position of anything that has left the grid entirely.
+	OffGrid = -1
+)
+
+// Fate describes what became of an object that left play without being
+// consumed.
+type Fate int
+
+const (
+	// FateInPlay means the object is still moving through the factory.
+	FateInPlay Fate = iota
+	// FateSpilled means the object was pushed off the factory floor onto the
+	// border of the grid.
+	FateSpilled
+	// FateLost means the object was pushed off the grid entirely, including a
+	// push off the end of a row that would otherwise wrap onto the next one.
+	FateLost
+	// FateWaiting means the object landed on a floor cell with no machine on
+	// it and was left waiting there for the rest of the run.
+	FateWaiting
+)
+
+// FatePenalties is the score value an object costs when it meets each fate.
+var FatePenalties = map[Fate]int{
+	FateSpilled: 1,
+	FateLost:    1,
+	FateWaiting: 0,
+}
+
+// FateName returns the name of a fate.
+func FateName(fate Fate) string {
+	switch fate {
+	case FateInPlay:
+		return "In Play"
+	case FateSpilled:
+		return "Spilled"
+	case FateLost:
+		return "Lost"
+	case FateWaiting:
+		return "Waiting"
+	default:
+		return "Unknown"
+	}
+}
+
+// GetAdjacentPosition returns the grid position adjacent to the given position in the specified orientation.
+// Moves that leave the grid, including moves off either end of a row, return OffGrid.
+func GetAdjacentPosition(pos int, orientation Orientation) int {
+	if pos < 0 || pos >= GridCols*GridRows {
+		return OffGrid
+	}
+	row := pos / GridCols
+	col := pos % GridCols
+
+	switch orientation {
+	case OrientationNorth:
+		row--
+	case OrientationSouth:
+		row++
+	case OrientationEast:
+		col++
+	case OrientationWest:
+		col--
+	}
+
+	if row < 0 || row >= GridRows || col < 0 || col >= GridCols {
+		return OffGrid
+	}
+	return row*GridCols + col
+}
+
+// IsFloor reports whether a position is on the factory floor, the part of the
+// grid inside the border where machines can be placed.
+func IsFloor(pos int) bool {
+	if pos < 0 || pos >= GridCols*GridRows {
+		return false
+	}
+	row := pos / GridCols
+	col := pos % GridCols
+	return row >= 1 && row < GridRows-1 && col >= 1 && col < GridCols-1
+}
+
+// fateAt returns the fate of an object that has just landed on pos.
+func fateAt(pos int, machines []*MachineState) Fate {
+	switch {
+	case pos < 0 || pos >= GridCols*GridRows:
+		return FateLost
+	case !IsFloor(pos):
+		return FateSpilled
+	case pos >= len(machines) || machines[pos] == nil:
+		return FateWaiting
+	default:
+		return FateInPlay
+	}
+}
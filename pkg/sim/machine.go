@@ -0,0 +1,149 @@
+package sim
+
+import (
+	"math/rand"
+)
+
+// MachineType represents the different kinds of machines.
+type MachineType int
+
+const (
+	MachineConveyor MachineType = iota
+	MachineProcessor
+	MachineMiner
+	MachineGeneralConsumer
+	MachineSplitter
+	MachineAmplifier
+	MachineCombiner
+	MachineBooster
+	MachineCatalyst
+	MachineSmelter
+	MachineAssembler
+	MachinePainter
+	MachineRepeater
+	MachineEngraver
+	MachinePolisher
+)
+
+// MachineRole represents the roles a machine can have.
+type MachineRole int
+
+const (
+	RoleProducer MachineRole = iota
+	RoleConsumer
+	RoleMover
+	RoleUpgrader
+)
+
+// MachineRoleName returns the name of a machine role.
+func MachineRoleName(role MachineRole) string {
+	switch role {
+	case RoleProducer:
+		return "Producer"
+	case RoleConsumer:
+		return "Consumer"
+	case RoleMover:
+		return "Mover"
+	case RoleUpgrader:
+		return "Upgrader"
+	default:
+		return "Unknown"
+	}
+}
+
+// Orientation represents the direction a machine is facing.
+type Orientation int
+
+const (
+	OrientationNorth Orientation = iota
+	OrientationEast
+	OrientationSouth
+	OrientationWest
+)
+
+// MachineState holds the simulation state of a placed machine. Memory is the
+// machine's own runtime state, kept between ticks and runs.
+type MachineState struct {
+	Machine     MachineInterface
+	Effects     []EffectInterface
+	Orientation Orientation
+	Memory      Memory
+}
+
+// CloneMachines returns a copy of the machines that a simulation can change
+// without touching the originals, so a run can be simulated alongside whatever
+// else is reading them. Cells covered by the same machine share one copy.
+func CloneMachines(machines []*MachineState) []*MachineState {
+	clones := make([]*MachineState, len(machines))
+	cloned := make(map[*MachineState]*MachineState)
+	for pos, ms := range machines {
+		if ms == nil {
+			continue
+		}
+		clone, ok := cloned[ms]
+		if !ok {
+			clone = &MachineState{Machine: ms.Machine.New(), Orientation: ms.Orientation, Memory: ms.Memory.Clone()}
+			for _, e := range ms.Effects {
+				clone.Effects = append(clone.Effects, e.Clone())
+			}
+			cloned[ms] = clone
+		}
+		clones[pos] = clone
+	}
+	return clones
+}
+
+// SyncMachines copies what a simulation left on each of the clones made by
+// CloneMachines, their effects and memory, back onto the machines they were
+// cloned from.
+func SyncMachines(machines, clones []*MachineState) {
+	for pos, ms := range machines {
+		if ms == nil || clones[pos] == nil {
+			continue
+		}
+		ms.Effects = clones[pos].Effects
+		ms.Memory = clones[pos].Memory
+	}
+}
+
+// Memory is runtime state a placed machine keeps between ticks and runs, such
+// as counters, cooldowns or which way it sent the last object. Process gets a
+// copy each tick, and the copy is only kept if the machine's changes go ahead,
+// so a blocked machine forgets what it did.
+type Memory map[string]int
+
+// Clone returns a copy of the memory that can be changed independently.
+func (m Memory) Clone() Memory {
+	clone := make(Memory, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
+// Equal reports whether two memories hold the same values. Missing keys count
+// as zero.
+func (m Memory) Equal(other Memory) bool {
+	for k, v := range m {
+		if other[k] != v {
+			return false
+		}
+	}
+	for k, v := range other {
+		if m[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MachineInterface defines the behavior for different machine types. What a
+// machine is called and looks like lives in its MachineSpec (see Register).
+type MachineInterface interface {
+	GetType() MachineType
+	New() MachineInterface
+	GetInputs() []Side
+	GetOutputs() []Side
+	Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change
+	EmitEffects(position int, machines []*MachineState) []EffectEmission
+}
@@ -0,0 +1,145 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// alternator is a test machine that sends objects alternately straight on and
+// to its right, remembering how many it has sent.
+type alternator struct{ Conveyor }
+
+func (a *alternator) New() MachineInterface { return &alternator{} }
+func (a *alternator) GetOutputs() []Side    { return []Side{SideFront, SideRight} }
+func (a *alternator) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	var changes []*Change
+	for _, obj := range history[len(history)-1] {
+		if obj.GridPosition != position {
+			continue
+		}
+		direction := orientation
+		if memory["sent"]%2 == 1 {
+			direction = (orientation + 1) % 4
+		}
+		memory["sent"]++
+		moved := *obj
+		moved.GridPosition = GetAdjacentPosition(position, direction)
+		changes = append(changes, &Change{StartObject: obj, EndObject: &moved})
+	}
+	return changes
+}
+
+// delay is a test machine that holds each object for two ticks before passing
+// it on.
+type delay struct{ Conveyor }
+
+func (d *delay) New() MachineInterface { return &delay{} }
+func (d *delay) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	var changes []*Change
+	for _, obj := range history[len(history)-1] {
+		if obj.GridPosition != position {
+			continue
+		}
+		if memory["held"] < 2 {
+			memory["held"]++
+			continue
+		}
+		memory["held"] = 0
+		moved := *obj
+		moved.GridPosition = GetAdjacentPosition(position, orientation)
+		changes = append(changes, &Change{StartObject: obj, EndObject: &moved})
+	}
+	return changes
+}
+
+func TestSimulateRunMemoryAlternates(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &alternator{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	consumedAt := make(map[int]int)
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				consumedAt[ch.StartObject.GridPosition]++
+			}
+		}
+	}
+	if consumedAt[cell(1, 3)] != 2 || consumedAt[cell(2, 2)] != 1 {
+		t.Errorf("Expected 2 objects straight on and 1 to the right, got %d and %d", consumedAt[cell(1, 3)], consumedAt[cell(2, 2)])
+	}
+	if got := machines[cell(1, 2)].Memory["sent"]; got != 3 {
+		t.Errorf("Expected the alternator to remember sending 3 objects, got %d", got)
+	}
+}
+
+func TestSimulateRunMemoryDelay(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &delay{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	// Holding an object changes nothing on the floor and blocks the miner, so
+	// the run has to keep going on the delay's memory alone. The miner only
+	// produces early in the run, so just the first object gets through.
+	if ids := consumedIDs(changes); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected object 1 to get through the delay, got %v", ids)
+	}
+	if got := machines[cell(1, 2)].Memory["held"]; got != 0 {
+		t.Errorf("Expected the delay to be empty-handed after the run, got held=%d", got)
+	}
+}
+
+func TestMemoryEqual(t *testing.T) {
+	if !(Memory{"a": 0}).Equal(nil) {
+		t.Error("Expected a zero value to equal a missing key")
+	}
+	if (Memory{"a": 1}).Equal(Memory{"a": 2}) {
+		t.Error("Expected different values to differ")
+	}
+}
+
+func TestCloneMachines(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	conveyor := &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast, Memory: Memory{"sent": 1}}
+	conveyor.addEffect(&Effect{Type: EffectBuffSpeed, Duration: 2, DurationType: DurationRun})
+	machines[cell(1, 1)] = conveyor
+	assembler := &MachineState{Machine: &Assembler{}, Orientation: OrientationEast}
+	if !Place(machines, cell(2, 2), assembler) {
+		t.Fatalf("Expected the assembler to fit")
+	}
+
+	clones := CloneMachines(machines)
+	clone := clones[cell(1, 1)]
+	if clone == conveyor {
+		t.Fatalf("Expected the conveyor to be copied")
+	}
+	for _, pos := range CoveredCells(cell(2, 2), assembler.Machine, assembler.Orientation) {
+		if clones[pos] != clones[cell(2, 2)] {
+			t.Errorf("Expected every cell of the assembler to share one copy, cell %d differs", pos)
+		}
+	}
+
+	clone.Memory["sent"]++
+	clone.expireEffects(DurationRun)
+	if conveyor.Memory["sent"] != 1 || conveyor.Effects[0].(*Effect).Duration != 2 {
+		t.Errorf("Expected changes to the copy to leave the original alone")
+	}
+
+	SyncMachines(machines, clones)
+	if conveyor.Memory["sent"] != 2 || conveyor.Effects[0].(*Effect).Duration != 1 {
+		t.Errorf("Expected syncing to copy the memory and effects back, got memory %v", conveyor.Memory)
+	}
+}
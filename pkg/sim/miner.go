@@ -0,0 +1,117 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Miner represents a miner machine. During a run fed from the player's hand
+// it emits the objects it was dealt; otherwise it mines objects of random
+// types. feed is nil when the miner was not dealt a share.
+type Miner struct {
+	feed []*Object
+}
+
+// feedMachine is implemented by machines that take objects from the hand fed
+// into the factory (see RunOptions).
+type feedMachine interface {
+	setFeed(objects []*Object)
+	clearFeed()
+}
+
+// feedKey is the memory key a machine that takes a feed keeps the index of the
+// next object of its share to emit under. The index only moves on when the
+// object goes out, so a blocked machine tries the same object again.
+const feedKey = "fed"
+
+func init() {
+	Register(MachineSpec{
+		Key:          "miner",
+		Name:         "Miner",
+		Description:  "Feeds the objects drawn for the run into the factory, one per tick.",
+		Roles:        []MachineRole{RoleProducer},
+		Color:        color.RGBA{R: 139, G: 69, B: 19, A: 255}, // Brown
+		Rarity:       RarityCommon,
+		Cost:         3,
+		DefaultCount: 2,
+		Machine:      &Miner{},
+	})
+}
+
+// New returns a fresh miner for a new placement.
+func (m *Miner) New() MachineInterface {
+	return &Miner{}
+}
+
+// GetType returns the machine type.
+func (m *Miner) GetType() MachineType {
+	return MachineMiner
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (m *Miner) GetInputs() []Side {
+	return nil
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (m *Miner) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// setFeed gives the miner its share of the hand for a run.
+func (m *Miner) setFeed(objects []*Object) {
+	m.feed = append([]*Object{}, objects...)
+}
+
+// clearFeed returns the miner to mining random objects once a run is over.
+func (m *Miner) clearFeed() {
+	m.feed = nil
+}
+
+// Process handles object interaction for miner.
+func (m *Miner) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	if m.feed != nil {
+		// Emit the next object of the miner's share of the hand each tick
+		i := memory[feedKey]
+		if i >= len(m.feed) {
+			return nil
+		}
+		memory[feedKey]++
+		template := m.feed[i]
+		// Hand objects dealt without a score are worth their type's base value
+		score := Score{Value: ObjectTypeOf(template.Type).BaseValue, MultAdd: 0, MultMult: 1}
+		if template.Score != nil {
+			score = *template.Score
+		}
+		start, end := score, score
+		nextPos := GetAdjacentPosition(position, orientation)
+		return []*Change{{
+			StartObject: &Object{GridPosition: position, Type: template.Type, Edition: template.Edition, Score: &start},
+			EndObject:   &Object{GridPosition: nextPos, Type: template.Type, Edition: template.Edition, Score: &end},
+			Score:       nil,
+		}}
+	}
+	if len(history) <= 3 {
+		// Emit one object of a random mined type per tick for first 3 ticks
+		mined := ObjectTypesTagged("mined")
+		objType := mined[rng.Intn(len(mined))]
+		value := ObjectTypeOf(objType).BaseValue
+
+		// Emit to next position based on orientation
+		nextPos := GetAdjacentPosition(position, orientation)
+
+		// fmt.Printf("processed miner. tick: %d, nextPos: %d, position: %d\n", tick, nextPos, position)
+		return []*Change{{
+			StartObject: &Object{GridPosition: position, Type: objType, Score: &Score{Value: value, MultAdd: 0, MultMult: 1}},
+			EndObject:   &Object{GridPosition: nextPos, Type: objType, Score: &Score{Value: value, MultAdd: 0, MultMult: 1}},
+			Score:       nil,
+		}}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from miner.
+func (m *Miner) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
@@ -0,0 +1,377 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// firstModType is the machine type given to the first mod machine loaded.
+// Later mods count up from it, clear of the built-in types.
+const firstModType MachineType = 1000
+
+// nextModType is the machine type the next mod machine loaded will get.
+var nextModType = firstModType
+
+// ModOp is one of the primitives a mod machine's behaviour is built from.
+type ModOp string
+
+const (
+	// ModMove sends the object out of the front of the machine.
+	ModMove ModOp = "move"
+	// ModTransform changes the object's type to the type keyed To, or moves
+	// it along its chain if To is "next" (see Transform).
+	ModTransform ModOp = "transform"
+	// ModAddValue adds Amount to the object's value.
+	ModAddValue ModOp = "add_value"
+	// ModAddMult adds Amount to the object's additive multiplier.
+	ModAddMult ModOp = "add_mult"
+	// ModSplit splits the object into two of half the value, sending one out
+	// of each side.
+	ModSplit ModOp = "split"
+	// ModConsume scores the object and pays $1 for it.
+	ModConsume ModOp = "consume"
+)
+
+// modRarities are the rarities a mod may name.
+var modRarities = map[string]Rarity{
+	"":         RarityCommon,
+	"common":   RarityCommon,
+	"uncommon": RarityUncommon,
+	"rare":     RarityRare,
+}
+
+// ModStep is one step of a mod machine's behaviour.
+type ModStep struct {
+	Op     ModOp  `json:"op"`
+	To     string `json:"to,omitempty"`
+	Amount int    `json:"amount,omitempty"`
+}
+
+// terminal reports whether the step decides where the object ends up, which
+// only the last step may do.
+func (s ModStep) terminal() bool {
+	return s.Op == ModMove || s.Op == ModSplit || s.Op == ModConsume
+}
+
+// ModDefinition is a machine defined in a mod file. Colour is written as
+// "#rrggbb", Rarity as "common", "uncommon" or "rare", and Count is how many
+// copies join the starting deck.
+//
+// A machine does what either Behaviour or Script says. Behaviour is applied to
+// each object in turn and must end with a move, split or consume step. Script
+// is the source of a Script, and Inputs names the sides a scripted machine
+// takes objects from; it defaults to back, left and right, or every side for
+// a script that only consumes.
+type ModDefinition struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Colour      string    `json:"colour"`
+	Rarity      string    `json:"rarity"`
+	Cost        int       `json:"cost"`
+	Count       *int      `json:"count"`
+	Behaviour   []ModStep `json:"behaviour"`
+	Script      string    `json:"script"`
+	Inputs      []string  `json:"inputs"`
+}
+
+// Mod is a loaded mod: the file it came from and the spec it registered.
+type Mod struct {
+	File string
+	Spec *MachineSpec
+}
+
+// LoadMods reads every .json file in dir as a ModDefinition, in name order,
+// and registers a machine for each. Definitions with unknown primitives,
+// colours or rarities, or whose ID is missing or already taken by a built-in
+// machine or an earlier mod, are skipped. It returns the mods it loaded along
+// with an error describing every definition it skipped.
+func LoadMods(dir string) ([]*Mod, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var mods []*Mod
+	var errs []error
+	for _, file := range files {
+		spec, err := loadMod(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mod %s: %w", filepath.Base(file), err))
+			continue
+		}
+		mods = append(mods, &Mod{File: filepath.Base(file), Spec: spec})
+	}
+	return mods, errors.Join(errs...)
+}
+
+// loadMod reads one mod file and registers its machine.
+func loadMod(file string) (*MachineSpec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var def ModDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	spec, err := def.spec(nextModType)
+	if err != nil {
+		return nil, err
+	}
+	if err := register(spec); err != nil {
+		return nil, err
+	}
+	nextModType++
+	registered, _ := SpecByKey(spec.Key)
+	return registered, nil
+}
+
+// spec checks the definition and builds the spec of its machine.
+func (d ModDefinition) spec(machineType MachineType) (MachineSpec, error) {
+	if d.ID == "" {
+		return MachineSpec{}, errors.New("missing id")
+	}
+	if _, ok := SpecByKey(d.ID); ok {
+		return MachineSpec{}, fmt.Errorf("id %q conflicts with a machine already loaded", d.ID)
+	}
+	rarity, ok := modRarities[strings.ToLower(d.Rarity)]
+	if !ok {
+		return MachineSpec{}, fmt.Errorf("unknown rarity %q", d.Rarity)
+	}
+	var c color.RGBA
+	if _, err := fmt.Sscanf(d.Colour, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return MachineSpec{}, fmt.Errorf("colour %q is not #rrggbb", d.Colour)
+	}
+	c.A = 255
+
+	roles := make(map[MachineRole]bool)
+	var machine MachineInterface
+	switch {
+	case d.Script != "" && len(d.Behaviour) > 0:
+		return MachineSpec{}, errors.New("give either a behaviour or a script, not both")
+	case d.Script != "":
+		scripted, err := d.scriptMachine(machineType, roles)
+		if err != nil {
+			return MachineSpec{}, err
+		}
+		machine = scripted
+	default:
+		if err := d.checkBehaviour(roles); err != nil {
+			return MachineSpec{}, err
+		}
+		machine = &ModMachine{machineType: machineType, steps: d.Behaviour}
+	}
+
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+	count := 1
+	if d.Count != nil {
+		count = *d.Count
+	}
+	spec := MachineSpec{
+		Key:          d.ID,
+		Name:         name,
+		Description:  d.Description,
+		Color:        c,
+		Rarity:       rarity,
+		Cost:         d.Cost,
+		DefaultCount: count,
+		Machine:      machine,
+	}
+	for role := RoleProducer; role <= RoleUpgrader; role++ {
+		if roles[role] {
+			spec.Roles = append(spec.Roles, role)
+		}
+	}
+	return spec, nil
+}
+
+// checkBehaviour checks the definition's behaviour steps and marks the roles
+// they give the machine.
+func (d ModDefinition) checkBehaviour(roles map[MachineRole]bool) error {
+	if len(d.Behaviour) == 0 || !d.Behaviour[len(d.Behaviour)-1].terminal() {
+		return errors.New("behaviour must end with move, split or consume")
+	}
+	for i, step := range d.Behaviour {
+		if step.terminal() && i != len(d.Behaviour)-1 {
+			return fmt.Errorf("step %d: %s must be the last step", i+1, step.Op)
+		}
+		switch step.Op {
+		case ModMove:
+			roles[RoleMover] = true
+		case ModTransform:
+			if _, ok := ObjectTypeByKey(step.To); !ok && step.To != "next" {
+				return fmt.Errorf("step %d: unknown object type %q", i+1, step.To)
+			}
+			roles[RoleUpgrader] = true
+		case ModAddValue, ModAddMult:
+			roles[RoleUpgrader] = true
+		case ModSplit:
+			roles[RoleMover] = true
+			roles[RoleProducer] = true
+		case ModConsume:
+			roles[RoleConsumer] = true
+		default:
+			return fmt.Errorf("step %d: unknown primitive %q", i+1, step.Op)
+		}
+	}
+	return nil
+}
+
+// scriptMachine parses the definition's script and builds its machine,
+// marking the roles the script gives it.
+func (d ModDefinition) scriptMachine(machineType MachineType, roles map[MachineRole]bool) (*ScriptMachine, error) {
+	script, err := ParseScript(d.Script)
+	if err != nil {
+		return nil, fmt.Errorf("script: %w", err)
+	}
+	outputs := script.Outputs()
+	consumes := script.uses(func(st scriptStmt) bool {
+		_, ok := st.(*consumeStmt)
+		return ok
+	})
+	upgrades := script.uses(func(st scriptStmt) bool {
+		set, ok := st.(*setStmt)
+		return ok && (set.name == "type" || set.name == "value" || set.name == "mult" || set.name == "times")
+	})
+	roles[RoleMover] = len(outputs) > 0
+	roles[RoleConsumer] = consumes
+	roles[RoleUpgrader] = upgrades
+
+	inputs := []Side{SideBack, SideLeft, SideRight}
+	if len(outputs) == 0 {
+		inputs = []Side{SideFront, SideRight, SideBack, SideLeft}
+	}
+	if d.Inputs != nil {
+		inputs = nil
+		for _, name := range d.Inputs {
+			side, ok := scriptSides[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("unknown input side %q", name)
+			}
+			inputs = append(inputs, side)
+		}
+	}
+	return &ScriptMachine{machineType: machineType, script: script, inputs: inputs, outputs: outputs}, nil
+}
+
+// ModMachine is a machine loaded from a mod, whose behaviour is a list of
+// primitive steps applied to each object that reaches it.
+type ModMachine struct {
+	machineType MachineType
+	steps       []ModStep
+}
+
+// New returns a fresh mod machine for a new placement.
+func (m *ModMachine) New() MachineInterface {
+	return &ModMachine{machineType: m.machineType, steps: m.steps}
+}
+
+// GetType returns the machine type.
+func (m *ModMachine) GetType() MachineType {
+	return m.machineType
+}
+
+// last returns the step that decides where objects end up.
+func (m *ModMachine) last() ModOp {
+	return m.steps[len(m.steps)-1].Op
+}
+
+// GetInputs returns the sides the machine accepts objects from. Consumers take
+// objects from every side and splitters only from behind.
+func (m *ModMachine) GetInputs() []Side {
+	switch m.last() {
+	case ModConsume:
+		return []Side{SideFront, SideRight, SideBack, SideLeft}
+	case ModSplit:
+		return []Side{SideBack}
+	default:
+		return []Side{SideBack, SideLeft, SideRight}
+	}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (m *ModMachine) GetOutputs() []Side {
+	switch m.last() {
+	case ModConsume:
+		return nil
+	case ModSplit:
+		return []Side{SideLeft, SideRight}
+	default:
+		return []Side{SideFront}
+	}
+}
+
+// Process runs the machine's steps on the first object on its cell, or on
+// every object for a consumer.
+func (m *ModMachine) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	var changes []*Change
+	for _, obj := range current {
+		if obj.GridPosition != position {
+			continue
+		}
+		changes = append(changes, m.apply(obj, position, orientation)...)
+		if m.last() != ModConsume {
+			break
+		}
+	}
+	return changes
+}
+
+// apply runs every step on one object and returns the changes it ends in.
+func (m *ModMachine) apply(obj *Object, position int, orientation Orientation) []*Change {
+	work := &Object{ID: obj.ID, GridPosition: position, Type: obj.Type, Edition: obj.Edition, Score: &Score{Value: obj.Score.Value, MultAdd: obj.Score.MultAdd, MultMult: obj.Score.MultMult}}
+	for _, step := range m.steps {
+		switch step.Op {
+		case ModTransform:
+			if step.To == "next" {
+				work.Type, _ = Transform(work.Type)
+			} else {
+				work.Type, _ = ObjectTypeByKey(step.To)
+			}
+			// A transformed object is a new object made from the old one.
+			work.ID = 0
+			work.ParentIDs = []int{obj.ID}
+		case ModAddValue:
+			work.Score.Value = saturatingAdd(work.Score.Value, step.Amount)
+		case ModAddMult:
+			work.Score.MultAdd = saturatingAdd(work.Score.MultAdd, step.Amount)
+		case ModMove:
+			work.GridPosition = GetAdjacentPosition(position, orientation)
+			return []*Change{{StartObject: obj, EndObject: work}}
+		case ModSplit:
+			half := work.Score.Value / 2
+			if half < 1 {
+				half = 1
+			}
+			var changes []*Change
+			for _, side := range []Side{SideLeft, SideRight} {
+				changes = append(changes, &Change{
+					StartObject: obj,
+					EndObject:   &Object{ParentIDs: []int{obj.ID}, GridPosition: GetAdjacentPosition(position, side.Facing(orientation)), Type: work.Type, Edition: work.Edition, Score: &Score{Value: half, MultAdd: work.Score.MultAdd, MultMult: work.Score.MultMult}},
+				})
+			}
+			return changes
+		case ModConsume:
+			return []*Change{{StartObject: obj, Score: work.FinalScore(), Payout: 1}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from the mod machine.
+func (m *ModMachine) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Mods cannot emit effects
+	return nil
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMods writes each mod definition to its own file in a new directory.
+func writeMods(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadModsReportsProblems(t *testing.T) {
+	dir := writeMods(t, map[string]string{
+		"a_polisher.json": `{"id": "test-polisher", "name": "Polisher", "colour": "#c0c0c0", "rarity": "uncommon", "cost": 4,
+			"behaviour": [{"op": "add_value", "amount": 3}, {"op": "transform", "to": "blue"}, {"op": "move"}]}`,
+		"b_again.json":    `{"id": "test-polisher", "colour": "#c0c0c0", "behaviour": [{"op": "move"}]}`,
+		"c_builtin.json":  `{"id": "conveyor", "colour": "#c0c0c0", "behaviour": [{"op": "move"}]}`,
+		"d_unknown.json":  `{"id": "test-teleporter", "colour": "#c0c0c0", "behaviour": [{"op": "teleport"}, {"op": "move"}]}`,
+		"e_rarity.json":   `{"id": "test-mythic", "colour": "#c0c0c0", "rarity": "mythic", "behaviour": [{"op": "move"}]}`,
+		"f_dangling.json": `{"id": "test-dangling", "colour": "#c0c0c0", "behaviour": [{"op": "add_mult", "amount": 1}]}`,
+		"readme.txt":      `not a mod`,
+	})
+
+	mods, err := LoadMods(dir)
+	if len(mods) != 1 || mods[0].Spec.Name != "Polisher" {
+		t.Fatalf("Expected only the polisher to load, got %d mods", len(mods))
+	}
+	spec := mods[0].Spec
+	if spec.Rarity != RarityUncommon || spec.Cost != 4 || spec.DefaultCount != 1 {
+		t.Errorf("Expected an uncommon $4 polisher with one copy, got %+v", spec)
+	}
+	if got, ok := SpecByKey("test-polisher"); !ok || got != spec {
+		t.Errorf("Expected the polisher to be registered under its id")
+	}
+
+	if err == nil {
+		t.Fatal("Expected the skipped mods to be reported, got no error")
+	}
+	for _, want := range []string{
+		`b_again.json: id "test-polisher" conflicts`,
+		`c_builtin.json: id "conveyor" conflicts`,
+		`d_unknown.json: step 1: unknown primitive "teleport"`,
+		`e_rarity.json: unknown rarity "mythic"`,
+		`f_dangling.json: behaviour must end with move, split or consume`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestModMachineBehaviour(t *testing.T) {
+	tests := []struct {
+		name      string
+		behaviour []ModStep
+		ends      []int
+		value     int
+		objType   ObjectType
+		payout    int
+	}{
+		{"move", []ModStep{{Op: ModAddValue, Amount: 2}, {Op: ModMove}}, []int{cell(2, 3)}, 3, ObjectRed, 0},
+		{"transform", []ModStep{{Op: ModTransform, To: "next"}, {Op: ModMove}}, []int{cell(2, 3)}, 1, ObjectGreen, 0},
+		{"split", []ModStep{{Op: ModAddValue, Amount: 3}, {Op: ModSplit}}, []int{cell(1, 2), cell(3, 2)}, 2, ObjectRed, 0},
+		{"consume", []ModStep{{Op: ModAddMult, Amount: 1}, {Op: ModConsume}}, nil, 1, ObjectRed, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machine := &ModMachine{machineType: firstModType, steps: tt.behaviour}
+			obj := &Object{ID: 1, GridPosition: cell(2, 2), Type: ObjectRed, Score: &Score{Value: 1, MultMult: 1}}
+			changes := machine.Process(cell(2, 2), [][]*Object{{obj}}, 0, OrientationEast, nil, Memory{})
+			if tt.ends == nil {
+				if len(changes) != 1 || changes[0].EndObject != nil || changes[0].Score.Value != tt.value || changes[0].Payout != tt.payout {
+					t.Fatalf("Expected the object to be consumed for %d, got %+v", tt.value, changes)
+				}
+				return
+			}
+			if len(changes) != len(tt.ends) {
+				t.Fatalf("Expected %d changes, got %d", len(tt.ends), len(changes))
+			}
+			for i, ch := range changes {
+				end := ch.EndObject
+				if end.GridPosition != tt.ends[i] || end.Score.Value != tt.value || end.Type != tt.objType {
+					t.Errorf("Expected object at %d worth %d of type %d, got %d worth %d of type %d", tt.ends[i], tt.value, tt.objType, end.GridPosition, end.Score.Value, end.Type)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package sim
+
+import "math"
+
+// ObjectType represents the different kinds of items that can move through the
+// factory. Types are defined as data in objects.json (see ObjectTypeOf); the
+// first three are always red, green and blue.
+type ObjectType int
+
+const (
+	ObjectRed ObjectType = iota
+	ObjectGreen
+	ObjectBlue
+)
+
+// Object represents an item moving through the factory.
+//
+// ID identifies the item across ticks. A machine that only moves or upgrades
+// an object keeps its ID; a machine that splits, combines or transforms
+// objects leaves ID at zero and lists the objects it was made from in
+// ParentIDs, and SimulateRun numbers it. IDs start at 1. Edition is the
+// modifier the object carries (see Edition).
+type Object struct {
+	ID           int
+	ParentIDs    []int
+	GridPosition int
+	Type         ObjectType
+	Score        *Score
+	Edition      Edition
+}
+
+// Score represents the scoring components. Machines and effects that raise a
+// score use saturatingAdd and saturatingMul, so a score that grows too large
+// for an int holds at the largest one rather than wrapping around.
+type Score struct {
+	Value    int
+	MultAdd  int
+	MultMult int
+}
+
+// saturatingAdd returns a+b, held at the largest or smallest int rather than
+// wrapping around.
+func saturatingAdd(a, b int) int {
+	sum := a + b
+	if b > 0 && sum < a {
+		return math.MaxInt
+	}
+	if b < 0 && sum > a {
+		return math.MinInt
+	}
+	return sum
+}
+
+// saturatingSub returns a-b, held at the largest or smallest int rather than
+// wrapping around.
+func saturatingSub(a, b int) int {
+	diff := a - b
+	if b < 0 && diff < a {
+		return math.MaxInt
+	}
+	if b > 0 && diff > a {
+		return math.MinInt
+	}
+	return diff
+}
+
+// saturatingMul returns a*b, held at the largest or smallest int rather than
+// wrapping around.
+func saturatingMul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		if (a < 0) == (b < 0) {
+			return math.MaxInt
+		}
+		return math.MinInt
+	}
+	return product
+}
+
+// Change represents a change to objects. Effects lists the machine effects
+// that modified the change, in the order they were applied. Fate records
+// whether the end object left play where it landed rather than carrying on.
+// Blocked marks an object that stayed where it was this tick, either because
+// its machine was blocked or because no machine took it. Payout is the money a
+// consumer pays for delivering the object. Recipe is the key of the recipe
+// that crafted the end object, if any. Retrigger counts how many times over the
+// machine was triggered to make the change: 0 for what the machine did itself,
+// 1 for the first retrigger of it, and so on (see retriggerMachine).
+// MachinePosition is the grid position of the machine that made the change,
+// or -1 for an object that stayed put because no machine moved it.
+type Change struct {
+	StartObject     *Object
+	EndObject       *Object
+	Score           *Score
+	Effects         []EffectType
+	Fate            Fate
+	Blocked         bool
+	Payout          int
+	Recipe          string
+	Retrigger       int
+	MachinePosition int
+}
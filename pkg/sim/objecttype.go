@@ -0,0 +1,215 @@
+package sim
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image/color"
+)
+
+// Shape is how objects of a type are drawn.
+type Shape int
+
+const (
+	ShapeSquare Shape = iota
+	ShapeCircle
+	ShapeDiamond
+	ShapeTriangle
+)
+
+// shapeNames are the names of shapes in objects.json.
+var shapeNames = map[string]Shape{
+	"square":   ShapeSquare,
+	"circle":   ShapeCircle,
+	"diamond":  ShapeDiamond,
+	"triangle": ShapeTriangle,
+}
+
+// ObjectTypeSpec describes a type of object. Tier is how far along its
+// production chain the type is, BaseValue is what a new object of the type is
+// worth, and Tags group types so machines can refer to them without naming
+// each one.
+type ObjectTypeSpec struct {
+	Key       string
+	Name      string
+	Color     color.RGBA
+	Shape     Shape
+	Tier      int
+	BaseValue int
+	Tags      []string
+}
+
+// HasTag reports whether the type carries a tag.
+func (s *ObjectTypeSpec) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// objectsJSON defines every object type, the transform chains between them
+// and the recipes that craft them. The first three types must be red, green and blue, in that order, to
+// match ObjectRed, ObjectGreen and ObjectBlue.
+//
+//go:embed objects.json
+var objectsJSON []byte
+
+var (
+	objectTypes      []*ObjectTypeSpec
+	objectTypesByKey = make(map[string]ObjectType)
+	// chains maps a tag to the type each type in the tag's chain becomes.
+	chains = make(map[string]map[ObjectType]ObjectType)
+	// chainTags lists the tags that have chains, in the order they were
+	// defined.
+	chainTags []string
+)
+
+// unknownObjectType describes object types that were never defined.
+var unknownObjectType = &ObjectTypeSpec{
+	Key:   "unknown",
+	Name:  "Unknown",
+	Color: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+}
+
+func init() {
+	if err := loadObjectTypes(objectsJSON); err != nil {
+		panic(fmt.Errorf("objects.json: %w", err))
+	}
+	for i, key := range []string{"red", "green", "blue"} {
+		if objectTypesByKey[key] != ObjectType(i) {
+			panic(fmt.Errorf("objects.json: type %d must be %q", i, key))
+		}
+	}
+}
+
+// loadObjectTypes reads object types, chains and recipes from JSON.
+func loadObjectTypes(data []byte) error {
+	var file struct {
+		Types []struct {
+			Key    string   `json:"key"`
+			Name   string   `json:"name"`
+			Colour string   `json:"colour"`
+			Shape  string   `json:"shape"`
+			Tier   int      `json:"tier"`
+			Value  int      `json:"value"`
+			Tags   []string `json:"tags"`
+		} `json:"types"`
+		Chains []struct {
+			Tag   string   `json:"tag"`
+			Steps []string `json:"steps"`
+		} `json:"chains"`
+		Recipes []recipeDefinition `json:"recipes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	for _, t := range file.Types {
+		if _, ok := objectTypesByKey[t.Key]; ok || t.Key == "" {
+			return fmt.Errorf("type key %q is missing or taken", t.Key)
+		}
+		shape, ok := shapeNames[t.Shape]
+		if !ok {
+			return fmt.Errorf("type %q: unknown shape %q", t.Key, t.Shape)
+		}
+		var c color.RGBA
+		if _, err := fmt.Sscanf(t.Colour, "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+			return fmt.Errorf("type %q: colour %q is not #rrggbb", t.Key, t.Colour)
+		}
+		c.A = 255
+		objectTypesByKey[t.Key] = ObjectType(len(objectTypes))
+		objectTypes = append(objectTypes, &ObjectTypeSpec{
+			Key:       t.Key,
+			Name:      t.Name,
+			Color:     c,
+			Shape:     shape,
+			Tier:      t.Tier,
+			BaseValue: t.Value,
+			Tags:      t.Tags,
+		})
+	}
+	for _, chain := range file.Chains {
+		if _, ok := chains[chain.Tag]; ok {
+			return fmt.Errorf("chain %q is defined twice", chain.Tag)
+		}
+		next := make(map[ObjectType]ObjectType)
+		for i := 0; i+1 < len(chain.Steps); i++ {
+			from, ok := objectTypesByKey[chain.Steps[i]]
+			if !ok {
+				return fmt.Errorf("chain %q: unknown type %q", chain.Tag, chain.Steps[i])
+			}
+			to, ok := objectTypesByKey[chain.Steps[i+1]]
+			if !ok {
+				return fmt.Errorf("chain %q: unknown type %q", chain.Tag, chain.Steps[i+1])
+			}
+			next[from] = to
+		}
+		chains[chain.Tag] = next
+		chainTags = append(chainTags, chain.Tag)
+	}
+	for _, def := range file.Recipes {
+		if err := addRecipe(def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectTypeOf returns the spec of an object type, or a placeholder named
+// "Unknown" if the type was never defined.
+func ObjectTypeOf(t ObjectType) *ObjectTypeSpec {
+	if t < 0 || int(t) >= len(objectTypes) {
+		return unknownObjectType
+	}
+	return objectTypes[t]
+}
+
+// ObjectTypeByKey returns the object type defined under a key.
+func ObjectTypeByKey(key string) (ObjectType, bool) {
+	t, ok := objectTypesByKey[key]
+	return t, ok
+}
+
+// ObjectTypes returns every object type, in order.
+func ObjectTypes() []ObjectType {
+	types := make([]ObjectType, len(objectTypes))
+	for i := range types {
+		types[i] = ObjectType(i)
+	}
+	return types
+}
+
+// ObjectTypesTagged returns every object type carrying a tag, in order.
+func ObjectTypesTagged(tag string) []ObjectType {
+	var types []ObjectType
+	for i, spec := range objectTypes {
+		if spec.HasTag(tag) {
+			types = append(types, ObjectType(i))
+		}
+	}
+	return types
+}
+
+// NextInChain returns the type that t becomes along the chain of a tag. It
+// reports false if the chain does not exist or t is at its end or not on it.
+func NextInChain(tag string, t ObjectType) (ObjectType, bool) {
+	next, ok := chains[tag][t]
+	return next, ok
+}
+
+// Transform returns the type that t becomes along the first chain, in the
+// order the chains were defined, whose tag t carries. It reports false if t
+// is on no chain or at the end of it.
+func Transform(t ObjectType) (ObjectType, bool) {
+	spec := ObjectTypeOf(t)
+	for _, tag := range chainTags {
+		if !spec.HasTag(tag) {
+			continue
+		}
+		if next, ok := NextInChain(tag, t); ok {
+			return next, true
+		}
+	}
+	return t, false
+}
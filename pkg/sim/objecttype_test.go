@@ -0,0 +1,83 @@
+package sim
+
+import "testing"
+
+func TestObjectTypeChains(t *testing.T) {
+	ore, _ := ObjectTypeByKey("ore")
+	ingot, _ := ObjectTypeByKey("ingot")
+	gear, _ := ObjectTypeByKey("gear")
+	tests := []struct {
+		name string
+		from ObjectType
+		want ObjectType
+		ok   bool
+	}{
+		{"red", ObjectRed, ObjectGreen, true},
+		{"green", ObjectGreen, ObjectBlue, true},
+		{"blue wraps", ObjectBlue, ObjectRed, true},
+		{"ore", ore, ingot, true},
+		{"ingot", ingot, gear, true},
+		{"gear is the end", gear, gear, false},
+	}
+	for _, tt := range tests {
+		got, ok := Transform(tt.from)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: expected %s (%v), got %s (%v)", tt.name, ObjectTypeOf(tt.want).Name, tt.ok, ObjectTypeOf(got).Name, ok)
+		}
+	}
+	if _, ok := NextInChain("colour", ore); ok {
+		t.Errorf("Expected ore to be off the colour chain")
+	}
+	if got := ObjectTypesTagged("mined"); len(got) != 3 {
+		t.Errorf("Expected red, green and blue to be mined, got %v", got)
+	}
+}
+
+func TestProcessorFollowsChains(t *testing.T) {
+	ore, _ := ObjectTypeByKey("ore")
+	ingot, _ := ObjectTypeByKey("ingot")
+	gear, _ := ObjectTypeByKey("gear")
+	tests := []struct {
+		name    string
+		from    ObjectType
+		want    ObjectType
+		multAdd int
+		keepID  bool
+	}{
+		{"charged green", ObjectGreen, ObjectBlue, 1, false},
+		{"ore refines", ore, ingot, 0, false},
+		{"gear passes through", gear, gear, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &Object{ID: 4, GridPosition: cell(2, 2), Type: tt.from, Score: &Score{Value: 1, MultMult: 1}}
+			changes := (&Processor{}).Process(cell(2, 2), [][]*Object{{obj}}, 0, OrientationEast, nil, Memory{})
+			if len(changes) != 1 {
+				t.Fatalf("Expected 1 change, got %d", len(changes))
+			}
+			end := changes[0].EndObject
+			if end.Type != tt.want || end.Score.MultAdd != tt.multAdd || (end.ID == obj.ID) != tt.keepID {
+				t.Errorf("Expected %s with +%d mult, keeping ID %v, got %s with +%d mult and ID %d",
+					ObjectTypeOf(tt.want).Name, tt.multAdd, tt.keepID, ObjectTypeOf(end.Type).Name, end.Score.MultAdd, end.ID)
+			}
+		})
+	}
+}
+
+func TestLoadObjectTypesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"bad shape", `{"types": [{"key": "widget", "colour": "#000000", "shape": "blob"}]}`},
+		{"bad colour", `{"types": [{"key": "widget", "colour": "black", "shape": "circle"}]}`},
+		{"taken key", `{"types": [{"key": "red", "colour": "#000000", "shape": "circle"}]}`},
+		{"unknown step", `{"chains": [{"tag": "widgets", "steps": ["red", "widget"]}]}`},
+		{"chain twice", `{"chains": [{"tag": "colour", "steps": ["red", "blue"]}]}`},
+	}
+	for _, tt := range tests {
+		if err := loadObjectTypes([]byte(tt.data)); err == nil {
+			t.Errorf("%s: expected an error, got none", tt.name)
+		}
+	}
+}
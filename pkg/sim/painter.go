@@ -0,0 +1,70 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Painter represents a painter machine.
+type Painter struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "painter",
+		Name:         "Painter",
+		Description:  "Moves objects forward, giving each object without an edition a random one: foil, holographic, polychrome or negative.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 255, G: 140, B: 200, A: 255}, // Pink
+		Rarity:       RarityRare,
+		Cost:         7,
+		DefaultCount: 1,
+		Machine:      &Painter{},
+	})
+}
+
+// New returns a fresh painter for a new placement.
+func (p *Painter) New() MachineInterface {
+	return &Painter{}
+}
+
+// GetType returns the machine type.
+func (p *Painter) GetType() MachineType {
+	return MachinePainter
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (p *Painter) GetInputs() []Side {
+	return []Side{SideBack}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (p *Painter) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for painter. Objects that already have
+// an edition keep it.
+func (p *Painter) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			edition := obj.Edition
+			if edition == EditionNone {
+				edition = Editions[rng.Intn(len(Editions))]
+			}
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from painter.
+func (p *Painter) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// No effects for now
+	return nil
+}
@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Polisher represents a polisher machine.
+type Polisher struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "polisher",
+		Name:         "Polisher",
+		Description:  "Moves objects forward and polishes adjacent machines for the rest of the round, doubling the mult multiplier of everything they output.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 255, G: 255, B: 220, A: 255}, // Pearl
+		Rarity:       RarityRare,
+		Cost:         8,
+		DefaultCount: 1,
+		Machine:      &Polisher{},
+	})
+}
+
+// New returns a fresh polisher for a new placement.
+func (p *Polisher) New() MachineInterface {
+	return &Polisher{}
+}
+
+// GetType returns the machine type.
+func (p *Polisher) GetType() MachineType {
+	return MachinePolisher
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (p *Polisher) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (p *Polisher) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for polisher.
+func (p *Polisher) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from polisher.
+func (p *Polisher) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Upgrade adjacent machines for the rest of the round
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				emissions = append(emissions, EffectEmission{
+					TargetGridX: nc,
+					TargetGridY: nr,
+					Effect: &Effect{
+						Type:         EffectShiny,
+						Duration:     1,
+						DurationType: DurationRound,
+					},
+				})
+			}
+		}
+	}
+	return emissions
+}
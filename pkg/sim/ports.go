@@ -0,0 +1,98 @@
+package sim
+
+// Side is a side of a machine's cell, relative to the way the machine faces.
+type Side int
+
+const (
+	SideFront Side = iota
+	SideRight
+	SideBack
+	SideLeft
+)
+
+// Facing returns the direction a side looks in when its machine faces the
+// given orientation.
+func (s Side) Facing(orientation Orientation) Orientation {
+	return Orientation((int(orientation) + int(s)) % 4)
+}
+
+// SideName returns the name of a side.
+func SideName(side Side) string {
+	switch side {
+	case SideFront:
+		return "Front"
+	case SideRight:
+		return "Right"
+	case SideBack:
+		return "Back"
+	case SideLeft:
+		return "Left"
+	default:
+		return "Unknown"
+	}
+}
+
+// portsAllow reports whether a change respects the ports of the machines at
+// either end. An object may only leave a machine through one of its output
+// sides, onto a cell just outside that side of its footprint, and may only
+// enter a machine through one of its input sides. Objects that stay within
+// their machine or leave play are not checked against the cell they land on.
+func portsAllow(ch *Change, machines []*MachineState) bool {
+	if ch.EndObject == nil {
+		return true
+	}
+	from, to := ch.StartObject.GridPosition, ch.EndObject.GridPosition
+	if from == to {
+		return true
+	}
+
+	var source *MachineState
+	if from >= 0 && from < len(machines) {
+		source = machines[from]
+	}
+	var target *MachineState
+	if to >= 0 && to < len(machines) {
+		target = machines[to]
+	}
+	if source != nil && target == source {
+		return true
+	}
+
+	var direction Orientation
+	found := false
+	if source != nil {
+		anchor := Anchor(machines, from)
+		for _, side := range source.Machine.GetOutputs() {
+			for _, exit := range ExitPositions(anchor, source.Machine, source.Orientation, side) {
+				if exit == to {
+					direction, found = side.Facing(source.Orientation), true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	} else {
+		for _, o := range []Orientation{OrientationNorth, OrientationEast, OrientationSouth, OrientationWest} {
+			if GetAdjacentPosition(from, o) == to {
+				direction, found = o, true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if ch.Fate != FateInPlay {
+		return true
+	}
+
+	// The object comes in through the side of the target facing back the way
+	// it came.
+	entry := (direction + 2) % 4
+	for _, side := range target.Machine.GetInputs() {
+		if side.Facing(target.Orientation) == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestSideFacing(t *testing.T) {
+	tests := []struct {
+		side        Side
+		orientation Orientation
+		want        Orientation
+	}{
+		{SideFront, OrientationEast, OrientationEast},
+		{SideRight, OrientationEast, OrientationSouth},
+		{SideBack, OrientationEast, OrientationWest},
+		{SideLeft, OrientationEast, OrientationNorth},
+		{SideLeft, OrientationNorth, OrientationWest},
+		{SideRight, OrientationWest, OrientationNorth},
+	}
+	for _, tt := range tests {
+		if got := tt.side.Facing(tt.orientation); got != tt.want {
+			t.Errorf("Expected %s of a machine facing %d to face %d, got %d", SideName(tt.side), tt.orientation, tt.want, got)
+		}
+	}
+}
+
+func TestPortsSplitterOutputsSideways(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(2, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(2, 2)] = &MachineState{Machine: &Splitter{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(3, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	consumedAt := make(map[int]int)
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				consumedAt[ch.StartObject.GridPosition]++
+			}
+		}
+	}
+	if consumedAt[cell(1, 2)] != 3 || consumedAt[cell(3, 2)] != 3 {
+		t.Errorf("Expected 3 halves out of each side, got %d left and %d right", consumedAt[cell(1, 2)], consumedAt[cell(3, 2)])
+	}
+}
+
+func TestPortsCombinerRefusesBack(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// The miner feeds the combiner from behind, which is not one of its inputs.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Combiner{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject != nil && ch.EndObject.GridPosition == cell(1, 2) {
+				t.Fatalf("Expected nothing to enter the combiner from behind, object %d did", ch.EndObject.ID)
+			}
+		}
+	}
+}
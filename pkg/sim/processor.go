@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Processor represents a processor machine.
+type Processor struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "processor",
+		Name:         "Processor",
+		Description:  "Transforms objects to the next type along their chain and moves them forward. Gives +1 multiplier when processing charged objects, such as green ones.",
+		Roles:        []MachineRole{RoleConsumer, RoleProducer, RoleMover},
+		Color:        color.RGBA{R: 100, G: 200, B: 100, A: 255},
+		Rarity:       RarityCommon,
+		Cost:         3,
+		DefaultCount: 2,
+		Machine:      &Processor{},
+	})
+}
+
+// New returns a fresh processor for a new placement.
+func (p *Processor) New() MachineInterface {
+	return &Processor{}
+}
+
+// GetType returns the machine type.
+func (p *Processor) GetType() MachineType {
+	return MachineProcessor
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (p *Processor) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (p *Processor) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for processor.
+func (p *Processor) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			multAdd := 0
+			if ObjectTypeOf(obj.Type).HasTag("charged") {
+				multAdd = 1
+			}
+			end := &Object{GridPosition: nextPos, Edition: obj.Edition, Score: &Score{Value: obj.Score.Value, MultAdd: saturatingAdd(obj.Score.MultAdd, multAdd), MultMult: obj.Score.MultMult}}
+			// Objects on no chain pass through as they are.
+			if next, ok := Transform(obj.Type); ok {
+				end.Type = next
+				end.ParentIDs = []int{obj.ID}
+			} else {
+				end.Type = obj.Type
+				end.ID = obj.ID
+			}
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   end,
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from processor.
+func (p *Processor) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
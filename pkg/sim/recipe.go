@@ -0,0 +1,124 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Recipe crafts a set of input objects into one output object. The crafted
+// object is worth the inputs' combined score plus the recipe's bonus.
+type Recipe struct {
+	Key    string
+	Inputs []ObjectType
+	Output ObjectType
+	Bonus  Score
+}
+
+// recipeDefinition is a recipe as it is written in objects.json. Times
+// defaults to 1 when it is left out.
+type recipeDefinition struct {
+	Key    string   `json:"key"`
+	Inputs []string `json:"inputs"`
+	Output string   `json:"output"`
+	Value  int      `json:"value"`
+	Mult   int      `json:"mult"`
+	Times  *int     `json:"times"`
+}
+
+var (
+	recipes      []*Recipe
+	recipesByKey = make(map[string]*Recipe)
+)
+
+// addRecipe checks a recipe definition and adds it to the recipe book.
+func addRecipe(def recipeDefinition) error {
+	if _, ok := recipesByKey[def.Key]; ok || def.Key == "" {
+		return fmt.Errorf("recipe key %q is missing or taken", def.Key)
+	}
+	if len(def.Inputs) < 2 {
+		return fmt.Errorf("recipe %q: needs at least two inputs", def.Key)
+	}
+	r := &Recipe{Key: def.Key, Bonus: Score{Value: def.Value, MultAdd: def.Mult, MultMult: 1}}
+	if def.Times != nil {
+		r.Bonus.MultMult = *def.Times
+	}
+	for _, key := range def.Inputs {
+		t, ok := objectTypesByKey[key]
+		if !ok {
+			return fmt.Errorf("recipe %q: unknown input %q", def.Key, key)
+		}
+		r.Inputs = append(r.Inputs, t)
+	}
+	output, ok := objectTypesByKey[def.Output]
+	if !ok {
+		return fmt.Errorf("recipe %q: unknown output %q", def.Key, def.Output)
+	}
+	r.Output = output
+	for _, other := range recipes {
+		if sameTypes(other.Inputs, r.Inputs) {
+			return fmt.Errorf("recipe %q: has the same inputs as %q", def.Key, other.Key)
+		}
+	}
+	recipes = append(recipes, r)
+	recipesByKey[r.Key] = r
+	return nil
+}
+
+// Recipes returns every recipe in the recipe book, in the order they were
+// defined.
+func Recipes() []*Recipe {
+	return append([]*Recipe(nil), recipes...)
+}
+
+// RecipeByKey returns the recipe defined under a key.
+func RecipeByKey(key string) (*Recipe, bool) {
+	r, ok := recipesByKey[key]
+	return r, ok
+}
+
+// FindRecipe returns the recipe whose inputs are exactly the types of the
+// objects given, in any order. It reports false if no recipe matches.
+func FindRecipe(objects []*Object) (*Recipe, bool) {
+	types := make([]ObjectType, len(objects))
+	for i, obj := range objects {
+		types[i] = obj.Type
+	}
+	for _, r := range recipes {
+		if sameTypes(r.Inputs, types) {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Craft returns the object a recipe makes from its inputs, heading for
+// position. Its score combines the inputs' scores with the recipe's bonus,
+// and it carries the highest of their editions.
+func (r *Recipe) Craft(inputs []*Object, position int) *Object {
+	score := &Score{Value: r.Bonus.Value, MultAdd: r.Bonus.MultAdd, MultMult: r.Bonus.MultMult}
+	var parentIDs []int
+	for _, obj := range inputs {
+		score.Value = saturatingAdd(score.Value, obj.Score.Value)
+		score.MultAdd = saturatingAdd(score.MultAdd, obj.Score.MultAdd)
+		score.MultMult = saturatingMul(score.MultMult, obj.Score.MultMult)
+		parentIDs = append(parentIDs, obj.ID)
+	}
+	return &Object{ParentIDs: parentIDs, GridPosition: position, Type: r.Output, Score: score, Edition: combinedEdition(inputs)}
+}
+
+// sameTypes reports whether two lists hold the same types, ignoring order.
+func sameTypes(a, b []ObjectType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]ObjectType(nil), a...)
+	b = append([]ObjectType(nil), b...)
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
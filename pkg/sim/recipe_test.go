@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// source is a test producer that emits one object of each of its types, in
+// order, one per tick, all of the same edition.
+type source struct {
+	stranger
+	types   []ObjectType
+	edition Edition
+}
+
+func (s *source) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	if len(history) > len(s.types) {
+		return nil
+	}
+	objType := s.types[len(history)-1]
+	value := ObjectTypeOf(objType).BaseValue
+	return []*Change{{
+		StartObject: &Object{GridPosition: position, Type: objType, Edition: s.edition, Score: &Score{Value: value, MultMult: 1}},
+		EndObject:   &Object{GridPosition: GetAdjacentPosition(position, orientation), Type: objType, Edition: s.edition, Score: &Score{Value: value, MultMult: 1}},
+	}}
+}
+
+// repeat returns n copies of the object type under a key.
+func repeat(t *testing.T, key string, n int) []ObjectType {
+	objType, ok := ObjectTypeByKey(key)
+	if !ok {
+		t.Fatalf("Expected an object type %q", key)
+	}
+	types := make([]ObjectType, n)
+	for i := range types {
+		types[i] = objType
+	}
+	return types
+}
+
+func TestFindRecipe(t *testing.T) {
+	red, _ := ObjectTypeByKey("red")
+	green, _ := ObjectTypeByKey("green")
+	blue, _ := ObjectTypeByKey("blue")
+	tests := []struct {
+		name  string
+		types []ObjectType
+		want  string
+	}{
+		{"red and blue", []ObjectType{red, blue}, "purple"},
+		{"blue and red", []ObjectType{blue, red}, "purple"},
+		{"three greens", []ObjectType{green, green, green}, "emerald"},
+		{"two greens", []ObjectType{green, green}, ""},
+		{"red and green", []ObjectType{red, green}, ""},
+		{"red, blue and green", []ObjectType{red, blue, green}, ""},
+	}
+	for _, tt := range tests {
+		objects := make([]*Object, len(tt.types))
+		for i, objType := range tt.types {
+			objects[i] = &Object{Type: objType}
+		}
+		recipe, ok := FindRecipe(objects)
+		got := ""
+		if ok {
+			got = recipe.Key
+		}
+		if got != tt.want {
+			t.Errorf("%s: Expected recipe %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRecipeCraft(t *testing.T) {
+	recipe, ok := RecipeByKey("emerald")
+	if !ok {
+		t.Fatal("Expected an emerald recipe")
+	}
+	inputs := []*Object{
+		{ID: 1, Score: &Score{Value: 1, MultAdd: 1, MultMult: 1}},
+		{ID: 2, Score: &Score{Value: 2, MultAdd: 0, MultMult: 3}},
+		{ID: 3, Score: &Score{Value: 3, MultAdd: 0, MultMult: 1}},
+	}
+	got := recipe.Craft(inputs, 7)
+	if got.Type != recipe.Output || got.GridPosition != 7 {
+		t.Errorf("Expected an emerald at 7, got type %d at %d", got.Type, got.GridPosition)
+	}
+	if got.Score.Value != 6+recipe.Bonus.Value || got.Score.MultAdd != 1+recipe.Bonus.MultAdd || got.Score.MultMult != 3*recipe.Bonus.MultMult {
+		t.Errorf("Expected the inputs' score plus the bonus, got %+v", *got.Score)
+	}
+	if len(got.ParentIDs) != 3 {
+		t.Errorf("Expected 3 parents, got %v", got.ParentIDs)
+	}
+}
+
+func TestCombinerPassesUnmatchedPairs(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Reds from the north and a green from the south have no recipe, so the
+	// reds pass through on their own and the green is left waiting.
+	machines[cell(1, 2)] = &MachineState{Machine: &source{types: repeat(t, "red", 2)}, Orientation: OrientationSouth}
+	machines[cell(3, 2)] = &MachineState{Machine: &source{types: repeat(t, "green", 1)}, Orientation: OrientationNorth}
+	machines[cell(2, 2)] = &MachineState{Machine: &Combiner{}, Orientation: OrientationEast}
+	machines[cell(2, 3)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	green, _ := ObjectTypeByKey("green")
+	var consumed []ObjectType
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.Recipe != "" {
+				t.Errorf("Expected nothing to be crafted, got %q", ch.Recipe)
+			}
+			if ch.EndObject == nil && ch.Score != nil {
+				consumed = append(consumed, ch.StartObject.Type)
+			}
+		}
+	}
+	if len(consumed) != 2 || consumed[0] == green || consumed[1] == green {
+		t.Errorf("Expected both reds to pass through, got %v", consumed)
+	}
+	last := changes[len(changes)-1]
+	if len(last) != 1 || last[0].StartObject.Type != green || last[0].Fate != FateWaiting {
+		t.Errorf("Expected the green to be left waiting in the combiner")
+	}
+}
@@ -0,0 +1,126 @@
+package sim
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// Rarity is how rarely a machine turns up.
+type Rarity int
+
+const (
+	RarityCommon Rarity = iota
+	RarityUncommon
+	RarityRare
+)
+
+// RarityName returns the name of a rarity.
+func RarityName(rarity Rarity) string {
+	switch rarity {
+	case RarityCommon:
+		return "Common"
+	case RarityUncommon:
+		return "Uncommon"
+	case RarityRare:
+		return "Rare"
+	default:
+		return "Unknown"
+	}
+}
+
+// MachineSpec is everything known about a machine type apart from how it
+// behaves. Key names the machine in deck definitions, Cost is its shop price
+// and DefaultCount is how many copies a starting deck holds when it does not
+// say. Machine is a prototype whose New method makes placeable instances.
+type MachineSpec struct {
+	Key          string
+	Name         string
+	Description  string
+	Roles        []MachineRole
+	Color        color.RGBA
+	Rarity       Rarity
+	Cost         int
+	DefaultCount int
+	Machine      MachineInterface
+}
+
+// Type returns the type of the machine the spec describes.
+func (s *MachineSpec) Type() MachineType {
+	return s.Machine.GetType()
+}
+
+// RoleNames returns the names of the machine's roles.
+func (s *MachineSpec) RoleNames() []string {
+	names := make([]string, len(s.Roles))
+	for i, role := range s.Roles {
+		names[i] = MachineRoleName(role)
+	}
+	return names
+}
+
+var (
+	specsByType = make(map[MachineType]*MachineSpec)
+	specsByKey  = make(map[string]*MachineSpec)
+)
+
+// unknownSpec describes machines that were never registered.
+var unknownSpec = &MachineSpec{
+	Key:   "unknown",
+	Name:  "Unknown",
+	Color: color.RGBA{R: 128, G: 128, B: 128, A: 255},
+}
+
+// Register adds a machine type to the registry. Every machine registers itself
+// once, from an init function in its own file. It panics if the type or key is
+// already taken.
+func Register(spec MachineSpec) {
+	if err := register(spec); err != nil {
+		panic(err)
+	}
+}
+
+// register adds a machine type to the registry, returning an error if the
+// spec is incomplete or its type or key is already taken.
+func register(spec MachineSpec) error {
+	if spec.Machine == nil || spec.Key == "" {
+		return fmt.Errorf("machine spec %q needs a key and a machine", spec.Name)
+	}
+	if existing, ok := specsByType[spec.Machine.GetType()]; ok {
+		return fmt.Errorf("machine type %d of %q is already registered by %q", spec.Machine.GetType(), spec.Key, existing.Key)
+	}
+	if _, ok := specsByKey[spec.Key]; ok {
+		return fmt.Errorf("machine key %q is already registered", spec.Key)
+	}
+	s := spec
+	specsByType[spec.Machine.GetType()] = &s
+	specsByKey[spec.Key] = &s
+	return nil
+}
+
+// SpecOf returns the registered spec of a machine's type, or a placeholder
+// spec named "Unknown" if the type was never registered.
+func SpecOf(machine MachineInterface) *MachineSpec {
+	if spec, ok := specsByType[machine.GetType()]; ok {
+		return spec
+	}
+	return unknownSpec
+}
+
+// SpecByKey returns the spec registered under a key.
+func SpecByKey(key string) (*MachineSpec, bool) {
+	spec, ok := specsByKey[key]
+	return spec, ok
+}
+
+// Specs returns every registered spec in machine type order.
+func Specs() []*MachineSpec {
+	specs := make([]*MachineSpec, 0, len(specsByType))
+	for _, spec := range specsByType {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Type() < specs[j].Type()
+	})
+	return specs
+}
@@ -0,0 +1,82 @@
+package sim
+
+import "testing"
+
+// stranger is a test machine whose type was never registered.
+type stranger struct{ Conveyor }
+
+func (s *stranger) GetType() MachineType { return -1 }
+
+func TestRegistry(t *testing.T) {
+	var specs []*MachineSpec
+	for _, spec := range Specs() {
+		// Other tests load mods, which register machines of their own.
+		if spec.Type() < firstModType {
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) != int(MachinePolisher)+1 {
+		t.Fatalf("Expected every machine type to be registered, got %d specs", len(specs))
+	}
+	for i, spec := range specs {
+		if spec.Type() != MachineType(i) {
+			t.Errorf("Expected spec %d to be machine type %d, got %d", i, i, spec.Type())
+		}
+		byKey, ok := SpecByKey(spec.Key)
+		if !ok || byKey != spec {
+			t.Errorf("Expected key %q to find %s", spec.Key, spec.Name)
+		}
+		if SpecOf(spec.Machine.New()) != spec {
+			t.Errorf("Expected a new %s to have its own spec", spec.Name)
+		}
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	tests := []struct {
+		name string
+		spec MachineSpec
+	}{
+		{"duplicate type", MachineSpec{Key: "another-conveyor", Machine: &Conveyor{}}},
+		{"duplicate key", MachineSpec{Key: "conveyor", Machine: &stranger{}}},
+		{"missing machine", MachineSpec{Key: "nothing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := register(tt.spec); err == nil {
+				t.Errorf("Expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestSpecOfUnregistered(t *testing.T) {
+	spec := SpecOf(&stranger{})
+	if spec.Name != "Unknown" {
+		t.Errorf("Expected an unregistered machine to be Unknown, got %q", spec.Name)
+	}
+}
+
+func TestUpgraderSpecs(t *testing.T) {
+	tests := []struct {
+		key    string
+		want   MachineType
+		effect EffectType
+	}{
+		{"engraver", MachineEngraver, EffectHolographic},
+		{"polisher", MachinePolisher, EffectShiny},
+	}
+	for _, tt := range tests {
+		spec, ok := SpecByKey(tt.key)
+		if !ok || spec.Type() != tt.want {
+			t.Fatalf("Expected %q to be registered as machine type %d", tt.key, tt.want)
+		}
+		machines := make([]*MachineState, GridCols*GridRows)
+		machines[cell(1, 1)] = &MachineState{Machine: spec.Machine.New()}
+		machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}}
+		emissions := spec.Machine.EmitEffects(cell(1, 1), machines)
+		if len(emissions) != 1 || emissions[0].Effect.GetType() != tt.effect || emissions[0].Effect.GetDurationType() != DurationRound {
+			t.Errorf("Expected a %s to give its neighbour a round-long effect %d", spec.Name, tt.effect)
+		}
+	}
+}
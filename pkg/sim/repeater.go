@@ -0,0 +1,87 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Repeater represents a repeater machine.
+type Repeater struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "repeater",
+		Name:         "Repeater",
+		Description:  "Moves objects forward and retriggers adjacent machines, so each does what it does once more.",
+		Roles:        []MachineRole{RoleMover},
+		Color:        color.RGBA{R: 150, G: 100, B: 255, A: 255}, // Violet
+		Rarity:       RarityRare,
+		Cost:         6,
+		DefaultCount: 1,
+		Machine:      &Repeater{},
+	})
+}
+
+// New returns a fresh repeater for a new placement.
+func (r *Repeater) New() MachineInterface {
+	return &Repeater{}
+}
+
+// GetType returns the machine type.
+func (r *Repeater) GetType() MachineType {
+	return MachineRepeater
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (r *Repeater) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (r *Repeater) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for repeater.
+func (r *Repeater) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			nextPos := GetAdjacentPosition(position, orientation)
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: obj.Score},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from repeater.
+func (r *Repeater) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Emit a retrigger to adjacent machines
+	var emissions []EffectEmission
+	pos := position
+	row := pos / GridCols
+	col := pos % GridCols
+	directions := []struct{ dx, dy int }{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	for _, dir := range directions {
+		nr, nc := row+dir.dx, col+dir.dy
+		if nr >= 0 && nr < GridRows && nc >= 0 && nc < GridCols && nr*GridCols+nc < len(machines) {
+			npos := nr*GridCols + nc
+			if machineState := machines[npos]; machineState != nil {
+				emissions = append(emissions, EffectEmission{
+					TargetGridX: nc,
+					TargetGridY: nr,
+					Effect: &Effect{
+						Type:         EffectRetrigger,
+						Duration:     1,
+						DurationType: DurationTick,
+					},
+				})
+			}
+		}
+	}
+	return emissions
+}
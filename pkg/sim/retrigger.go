@@ -0,0 +1,58 @@
+package sim
+
+// maxRetriggerDepth is the most times over a change may be retriggered, however
+// many retriggers its machine was given.
+const maxRetriggerDepth = 5
+
+// retriggerMachine is implemented by machines that can be triggered again on
+// something they have just done. Retrigger returns the change that doing it
+// again makes, or nil if there is nothing to do again. A retrigger that moves
+// an object carries on from the end object of the change it is given, and
+// must not modify that change.
+type retriggerMachine interface {
+	Retrigger(change *Change) *Change
+}
+
+// retrigger follows each change with one retrigger of it for each retrigger
+// the machine has, up to maxRetriggerDepth, each carrying on from the last.
+// Retriggers are recorded as changes of their own, straight after the change
+// they retrigger.
+func retrigger(ms *MachineState, changes []*Change) []*Change {
+	m, ok := ms.Machine.(retriggerMachine)
+	times := ms.countEffect(EffectRetrigger)
+	if !ok || times == 0 {
+		return changes
+	}
+	if times > maxRetriggerDepth {
+		times = maxRetriggerDepth
+	}
+	var retriggered []*Change
+	for _, ch := range changes {
+		retriggered = append(retriggered, ch)
+		last := ch
+		for depth := 1; depth <= times; depth++ {
+			next := m.Retrigger(last)
+			if next == nil {
+				break
+			}
+			next.Retrigger = depth
+			next.Effects = append(next.Effects, EffectRetrigger)
+			retriggered = append(retriggered, next)
+			last = next
+		}
+	}
+	return retriggered
+}
+
+// carriedOn returns the end objects that a retrigger among changes carries on
+// from. They are replaced by the retrigger's end object, so they never reach
+// the floor.
+func carriedOn(changes []*Change) map[*Object]bool {
+	carried := make(map[*Object]bool)
+	for _, ch := range changes {
+		if ch.Retrigger > 0 && ch.EndObject != nil {
+			carried[ch.StartObject] = true
+		}
+	}
+	return carried
+}
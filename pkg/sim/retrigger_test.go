@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRepeaterRetriggersAmplifier(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1)}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Amplifier{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 2)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	var doubled []*Change
+	var consumed []*Score
+	for _, tickChanges := range changes {
+		for i, ch := range tickChanges {
+			if (ch.StartObject.GridPosition == cell(1, 2) && !ch.Blocked) || (ch.Retrigger > 0 && ch.EndObject != nil) {
+				doubled = append(doubled, ch)
+				if ch.Retrigger > 0 && (i == 0 || tickChanges[i-1].EndObject != ch.StartObject) {
+					t.Errorf("Expected the retrigger to carry on from the change before it")
+				}
+			}
+			if Consumed(ch) {
+				consumed = append(consumed, ch.Score)
+			}
+		}
+	}
+	if len(doubled) != 2 || doubled[0].Retrigger != 0 || doubled[1].Retrigger != 1 {
+		t.Fatalf("Expected the amplifier's change followed by one retrigger, got %d changes", len(doubled))
+	}
+	if got := doubled[1].EndObject.Score.Value; got != 4 {
+		t.Errorf("Expected the retriggered amplifier to double the red twice to 4, got %d", got)
+	}
+	if len(consumed) != 1 || consumed[0].Value != 4 {
+		t.Errorf("Expected one red scoring 4, got %v", consumed)
+	}
+}
+
+func TestRepeaterRetriggersConsumer(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1)}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 2)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	var scored []*Change
+	payout := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if Consumed(ch) {
+				scored = append(scored, ch)
+			}
+			payout += ch.Payout
+		}
+	}
+	if len(scored) != 2 || scored[1].Retrigger != 1 || scored[1].StartObject != scored[0].StartObject {
+		t.Fatalf("Expected the red to be scored and then scored again by a retrigger, got %d scorings", len(scored))
+	}
+	if total := ScoreRun(changes, nil).RoundScore.Int64(); total != 2 {
+		t.Errorf("Expected the red to score 1 twice, got %d", total)
+	}
+	if payout != 1 {
+		t.Errorf("Expected only the first scoring to pay out, got $%d", payout)
+	}
+}
+
+func TestAdjacentRepeaters(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1)}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Amplifier{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+	// Each repeater retriggers the other as well as the machine above it, but
+	// a retriggered repeater does not retrigger again.
+	machines[cell(2, 2)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+	machines[cell(2, 3)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	retriggers := make(map[int]int)
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.Retrigger > 0 {
+				retriggers[ch.MachinePosition]++
+				if ch.Retrigger != 1 {
+					t.Errorf("Expected no retrigger deeper than 1, got %d", ch.Retrigger)
+				}
+			}
+		}
+	}
+	want := map[int]int{cell(1, 2): 1, cell(1, 3): 1}
+	if len(retriggers) != len(want) {
+		t.Errorf("Expected retriggers at %v, got %v", want, retriggers)
+	}
+	for pos, n := range want {
+		if retriggers[pos] != n {
+			t.Errorf("Expected %d retrigger at %d, got %d", n, pos, retriggers[pos])
+		}
+	}
+	// Doubled twice to 4, then scored twice.
+	if total := ScoreRun(changes, nil).RoundScore.Int64(); total != 8 {
+		t.Errorf("Expected a round score of 8, got %d", total)
+	}
+}
+
+func TestRetriggerDepthLimit(t *testing.T) {
+	ms := &MachineState{Machine: &Amplifier{}, Orientation: OrientationEast}
+	for i := 0; i < maxRetriggerDepth+2; i++ {
+		ms.addEffect(&Effect{Type: EffectRetrigger, Duration: 1, DurationType: DurationTick})
+	}
+	obj := &Object{ID: 1, GridPosition: cell(1, 1), Score: &Score{Value: 1, MultMult: 1}}
+
+	changes := processMachine(cell(1, 1), ms, [][]*Object{{obj}}, 0, rand.New(rand.NewSource(1)), Memory{})
+	if len(changes) != maxRetriggerDepth+1 {
+		t.Fatalf("Expected the change and %d retriggers, got %d changes", maxRetriggerDepth, len(changes))
+	}
+	for depth, ch := range changes {
+		if ch.Retrigger != depth {
+			t.Errorf("Expected change %d to be at depth %d, got %d", depth, depth, ch.Retrigger)
+		}
+		if ch.MachinePosition != cell(1, 1) {
+			t.Errorf("Expected change %d to record the amplifier's position, got %d", depth, ch.MachinePosition)
+		}
+	}
+	if got, want := changes[maxRetriggerDepth].EndObject.Score.Value, 2<<maxRetriggerDepth; got != want {
+		t.Errorf("Expected the red to be doubled %d times to %d, got %d", maxRetriggerDepth+1, want, got)
+	}
+}
@@ -0,0 +1,407 @@
+package sim
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxTicks is the longest a run may take before it is treated as looping.
+	maxTicks = 1000
+	// maxObjects is the most objects that may be on the floor at once.
+	maxObjects = 200
+)
+
+// SimulateRun simulates the entire run sequence. All randomness during the run
+// is drawn from an RNG seeded with seed, so the same machines and seed always
+// produce the same changes.
+//
+// Each tick, every machine first emits its effects onto its neighbours, then
+// every machine proposes what to do with the objects on its cell with its
+// attached effects applied and any retriggers it was given following on, and
+// finally effects count down and expire. All
+// proposals are resolved against each other at once (see resolveMoves), so
+// objects move simultaneously and never exceed a cell's capacity. The run ends
+// when no machine has anything to do, or when everything left on the floor is
+// stuck, in which case those objects are left waiting.
+//
+// Machines remember things between ticks in their MachineState.Memory. Each
+// tick a machine works on a copy of its memory, and the copy replaces the
+// original unless the machine was blocked. A machine whose memory changed
+// counts as progress, so a machine counting down a cooldown keeps the run
+// going even while nothing moves.
+//
+// A machine that covers several cells holds the same *MachineState in each of
+// them (see Place). It is processed once, at its top-left cell, and objects
+// may enter it through any of its cells.
+//
+// If the factory revisits a state it has already been in, runs past maxTicks
+// or floods the floor with more than maxObjects objects, SimulateRun stops and
+// returns the changes so far along with a *RunError wrapping ErrInfiniteLoop
+// or ErrObjectExplosion.
+//
+// A mod script that fails on an object leaves the object where it is and the
+// run carries on. Each failure is returned as a *ScriptError, joined with any
+// *RunError.
+func SimulateRun(machines []*MachineState, seed int64) ([][]*Change, error) {
+	return SimulateRunWith(machines, seed, RunOptions{})
+}
+
+// RunOptions holds what the player brings to a run besides the machines.
+//
+// Hand is fed into the factory instead of letting producers make their own
+// objects. It is dealt out in order, one object at a time, to each machine
+// that takes a feed in grid order (see feedMachine), and each machine emits
+// its share one object per tick. A nil hand leaves producers to make their
+// own objects; an empty hand feeds them nothing.
+//
+// Foremen see every change that goes ahead, in order (see Foreman).
+type RunOptions struct {
+	Hand    []*Object
+	Foremen []Foreman
+}
+
+// SimulateRunWith simulates a run like SimulateRun with the hand and foremen
+// in opts.
+func SimulateRunWith(machines []*MachineState, seed int64, opts RunOptions) (_ [][]*Change, err error) {
+	defer func() {
+		if failed := machineErrors(machines); len(failed) > 0 {
+			err = errors.Join(append([]error{err}, failed...)...)
+		}
+	}()
+	if opts.Hand != nil {
+		feeders := dealHand(machines, opts.Hand)
+		defer func() {
+			for _, m := range feeders {
+				m.clearFeed()
+			}
+		}()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	nextID := 1
+	history := [][]*Object{{}}
+	allChanges := [][]*Change{}
+	seen := make(map[string]bool)
+
+	defer func() {
+		for pos, ms := range machines {
+			if ms != nil && isAnchor(machines, pos) {
+				ms.expireEffects(DurationRun)
+			}
+		}
+	}()
+
+	for tick := 0; tick < maxTicks; tick++ {
+		emitEffects(machines)
+
+		var proposals [][]*Change
+		var proposers []int
+		memories := make(map[int]Memory)
+		for pos, ms := range machines {
+			if ms == nil || !isAnchor(machines, pos) {
+				continue
+			}
+			memory := ms.Memory.Clone()
+			memories[pos] = memory
+			chs := processMachine(pos, ms, history, tick, rng, memory)
+			for _, change := range chs {
+				if change.EndObject != nil {
+					settleFate(change, fateAt(change.EndObject.GridPosition, machines))
+				}
+			}
+			if len(chs) > 0 {
+				proposals = append(proposals, chs)
+				proposers = append(proposers, pos)
+			}
+		}
+
+		for pos, ms := range machines {
+			if ms != nil && isAnchor(machines, pos) {
+				ms.expireEffects(DurationTick)
+			}
+		}
+
+		changes, blocked := resolveMoves(history[len(history)-1], proposals, machines)
+		moved := false
+		for i, pos := range proposers {
+			if blocked[i] {
+				delete(memories, pos)
+			} else {
+				moved = true
+			}
+		}
+		remembered := commitMemories(machines, memories)
+		for i, pos := range proposers {
+			if blocked[i] {
+				continue
+			}
+			for _, change := range proposals[i] {
+				for _, f := range opts.Foremen {
+					f.OnChange(tick, machines[pos].Machine, change)
+				}
+			}
+		}
+		if len(changes) == 0 && !remembered {
+			break
+		}
+		if !moved && !remembered {
+			// Nothing can move any more, so whatever is left is stuck.
+			for _, change := range changes {
+				settleFate(change, FateWaiting)
+			}
+			allChanges = append(allChanges, changes)
+			break
+		}
+		history = append(history, []*Object{})
+		allChanges = append(allChanges, changes)
+		produced := false
+		carried := carriedOn(changes)
+		for _, change := range changes {
+			if change.EndObject == nil {
+				continue
+			}
+			if change.EndObject.ID == 0 && change.Retrigger > 0 {
+				// A retrigger carries on with the object it started from.
+				change.EndObject.ID = change.StartObject.ID
+			}
+			if change.EndObject.ID == 0 {
+				change.EndObject.ID = nextID
+				nextID++
+				// Objects a producer creates out of nothing start life as
+				// the same object they end the tick as.
+				if change.StartObject != nil && change.StartObject.ID == 0 {
+					change.StartObject.ID = change.EndObject.ID
+					produced = true
+				}
+			}
+			if change.Fate != FateInPlay || carried[change.EndObject] {
+				continue
+			}
+			history[tick+1] = append(history[tick+1], change.EndObject)
+		}
+
+		current := history[tick+1]
+		if len(current) > maxObjects {
+			return allChanges, &RunError{Err: ErrObjectExplosion, Tick: tick + 1, Cells: occupiedCells(current)}
+		}
+		// Producers count ticks rather than look at the floor, so a state
+		// seen before something was produced can come round again without
+		// the factory looping.
+		if produced {
+			seen = make(map[string]bool)
+		}
+		key := worldKey(current, machines)
+		if len(current) > 0 && seen[key] {
+			return allChanges, &RunError{Err: ErrInfiniteLoop, Tick: tick + 1, Cells: occupiedCells(current)}
+		}
+		seen[key] = true
+	}
+
+	if len(allChanges) == maxTicks {
+		return allChanges, &RunError{Err: ErrInfiniteLoop, Tick: maxTicks, Cells: occupiedCells(history[len(history)-1])}
+	}
+	return allChanges, nil
+}
+
+// errorMachine is implemented by machines that can fail during a run without
+// stopping it. takeErrors returns the failures since it was last called.
+type errorMachine interface {
+	takeErrors() []error
+}
+
+// machineErrors collects the failures of every machine during a run, in grid
+// order.
+func machineErrors(machines []*MachineState) []error {
+	var errs []error
+	for pos, ms := range machines {
+		if ms == nil || !isAnchor(machines, pos) {
+			continue
+		}
+		if m, ok := ms.Machine.(errorMachine); ok {
+			errs = append(errs, m.takeErrors()...)
+		}
+	}
+	return errs
+}
+
+// settleFate records what becomes of a change's end object, charging the
+// fate's penalty to changes that do not already score. A retriggered object is
+// only charged once.
+func settleFate(change *Change, fate Fate) {
+	change.Fate = fate
+	if change.Fate == FateInPlay || change.Score != nil || change.Retrigger > 0 {
+		return
+	}
+	if penalty := FatePenalties[change.Fate]; penalty != 0 {
+		change.Score = &Score{Value: -penalty, MultAdd: 0, MultMult: 1}
+	}
+}
+
+// commitMemories replaces the memory of each machine in memories with its
+// updated copy, and reports whether any machine's memory changed.
+func commitMemories(machines []*MachineState, memories map[int]Memory) bool {
+	changed := false
+	for pos, memory := range memories {
+		ms := machines[pos]
+		if !memory.Equal(ms.Memory) {
+			changed = true
+		}
+		ms.Memory = memory
+	}
+	return changed
+}
+
+// dealHand shares the objects in hand out between the machines that take a
+// feed, in grid order, starts each of them at the first object of its share,
+// and returns those machines.
+func dealHand(machines []*MachineState, hand []*Object) []feedMachine {
+	var feeders []feedMachine
+	for pos, ms := range machines {
+		if ms == nil || !isAnchor(machines, pos) {
+			continue
+		}
+		if m, ok := ms.Machine.(feedMachine); ok {
+			feeders = append(feeders, m)
+			delete(ms.Memory, feedKey)
+		}
+	}
+	shares := make([][]*Object, len(feeders))
+	for i, obj := range hand {
+		if len(feeders) == 0 {
+			break
+		}
+		shares[i%len(feeders)] = append(shares[i%len(feeders)], obj)
+	}
+	for i, m := range feeders {
+		m.setFeed(shares[i])
+	}
+	return feeders
+}
+
+// scoreReader is implemented by machines whose behaviour can depend on the
+// scores of the objects they handle.
+type scoreReader interface {
+	readsScores() bool
+}
+
+// worldKey identifies the objects on the floor at the end of a tick by
+// position, type and edition, along with every machine's memory. Machines only
+// look at the objects on the floor and their own memory, so a repeated key
+// means the factory will repeat itself forever.
+//
+// Objects are not told apart by ID, since no machine looks at IDs and a
+// transformed object gets a new one every time. Scores only count if a machine
+// that reads them is placed (see scoreReader); otherwise an object whose score
+// grows on every lap of a loop would stop the loop ever being caught.
+func worldKey(objects []*Object, machines []*MachineState) string {
+	withScores := false
+	for _, ms := range machines {
+		if ms == nil {
+			continue
+		}
+		if m, ok := ms.Machine.(scoreReader); ok && m.readsScores() {
+			withScores = true
+		}
+	}
+	parts := make([]string, len(objects))
+	for i, obj := range objects {
+		parts[i] = strconv.Itoa(obj.GridPosition) + ":" + strconv.Itoa(int(obj.Type)) + "/" + strconv.Itoa(int(obj.Edition))
+		if withScores && obj.Score != nil {
+			parts[i] += "=" + strconv.Itoa(obj.Score.Value) + "+" + strconv.Itoa(obj.Score.MultAdd) + "x" + strconv.Itoa(obj.Score.MultMult)
+		}
+	}
+	sort.Strings(parts)
+	for pos, ms := range machines {
+		if ms == nil || len(ms.Memory) == 0 || !isAnchor(machines, pos) {
+			continue
+		}
+		keys := make([]string, 0, len(ms.Memory))
+		for k, v := range ms.Memory {
+			if v != 0 {
+				keys = append(keys, k+"="+strconv.Itoa(v))
+			}
+		}
+		sort.Strings(keys)
+		parts = append(parts, "m"+strconv.Itoa(pos)+"{"+strings.Join(keys, ",")+"}")
+	}
+	return strings.Join(parts, ",")
+}
+
+// occupiedCells returns the sorted grid positions holding at least one object.
+func occupiedCells(objects []*Object) []int {
+	var cells []int
+	seen := make(map[int]bool)
+	for _, obj := range objects {
+		if !seen[obj.GridPosition] {
+			seen[obj.GridPosition] = true
+			cells = append(cells, obj.GridPosition)
+		}
+	}
+	sort.Ints(cells)
+	return cells
+}
+
+// emitEffects collects the effects every machine emits and attaches them to
+// the machines they target.
+func emitEffects(machines []*MachineState) {
+	for pos, ms := range machines {
+		if ms == nil || !isAnchor(machines, pos) {
+			continue
+		}
+		for _, emission := range ms.Machine.EmitEffects(pos, machines) {
+			target := emission.TargetGridY*GridCols + emission.TargetGridX
+			if target < 0 || target >= len(machines) || machines[target] == nil {
+				continue
+			}
+			machines[target].addEffect(emission.Effect)
+		}
+	}
+}
+
+// processMachine runs a machine for one tick and applies its effects to the
+// changes it produces. A machine with EffectBuffSpeed gets a second pass over
+// the objects on its cell that it did not take the first time, and a machine
+// with EffectRetrigger does everything again (see retrigger).
+func processMachine(pos int, ms *MachineState, history [][]*Object, tick int, rng *rand.Rand, memory Memory) []*Change {
+	changes := ms.Machine.Process(pos, history, tick, ms.Orientation, rng, memory)
+
+	if ms.hasEffect(EffectBuffSpeed) {
+		current := history[len(history)-1]
+		taken := make(map[*Object]bool)
+		for _, ch := range changes {
+			taken[ch.StartObject] = true
+		}
+		var remaining []*Object
+		for _, obj := range current {
+			if !taken[obj] {
+				remaining = append(remaining, obj)
+			}
+		}
+		// Only machines that took an object from the grid get a second pass;
+		// producers would otherwise emit twice.
+		if len(remaining) < len(current) {
+			extraHistory := append(history[:len(history)-1:len(history)-1], remaining)
+			extra := ms.Machine.Process(pos, extraHistory, tick, ms.Orientation, rng, memory)
+			for _, ch := range extra {
+				ch.Effects = append(ch.Effects, EffectBuffSpeed)
+			}
+			changes = append(changes, extra...)
+		}
+	}
+
+	for _, ch := range changes {
+		for _, e := range ms.Effects {
+			if e.Apply(ch) {
+				ch.Effects = append(ch.Effects, e.GetType())
+			}
+		}
+	}
+	changes = retrigger(ms, changes)
+	for _, ch := range changes {
+		ch.MachinePosition = pos
+	}
+	return changes
+}
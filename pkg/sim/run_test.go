@@ -0,0 +1,457 @@
+package sim
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestSimulateRun(t *testing.T) {
+	// Test with a simple setup: miner machine emitting to conveyor to end
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[1*GridCols+1] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[1*GridCols+2] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[1*GridCols+3] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	if len(changes) == 0 {
+		t.Error("Expected some changes, got none")
+	}
+
+	// Check that miner emits, conveyor moves, end consumes
+	consumed := 0
+	payout := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.EndObject == nil && ch.Score != nil {
+				consumed++
+			}
+			payout += ch.Payout
+			if ch.Fate != FateInPlay {
+				t.Errorf("Expected every object to stay in play, got %s", FateName(ch.Fate))
+			}
+		}
+	}
+	if consumed != 3 {
+		t.Errorf("Expected the consumer to take all 3 mined objects, got %d", consumed)
+	}
+	if payout != 3 {
+		t.Errorf("Expected the consumer to pay $1 per object, got $%d", payout)
+	}
+}
+
+func TestSimulateRunNoMachines(t *testing.T) {
+	machines := make([]*MachineState, 49)
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %d ticks", len(changes))
+	}
+}
+
+func TestSimulateRunDeterministic(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[1*GridCols+1] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[1*GridCols+2] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	emitted := func(seed int64) []ObjectType {
+		changes, err := SimulateRun(machines, seed)
+		if err != nil {
+			t.Fatalf("SimulateRun failed: %v", err)
+		}
+		var types []ObjectType
+		for _, tickChanges := range changes {
+			for _, ch := range tickChanges {
+				if ch.StartObject.GridPosition == 1*GridCols+1 {
+					types = append(types, ch.StartObject.Type)
+				}
+			}
+		}
+		return types
+	}
+
+	first := emitted(42)
+	second := emitted(42)
+	if len(first) != 3 {
+		t.Fatalf("Expected 3 emitted objects, got %d", len(first))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Emission %d differs between runs with the same seed: %v != %v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestSimulateRunEffects(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Miner at (1,1) emitting east into a consumer, with an Amplifier below
+	// the miner boosting it.
+	miner := &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[1*GridCols+1] = miner
+	machines[1*GridCols+2] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+	machines[2*GridCols+1] = &MachineState{Machine: &Amplifier{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	consumed := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.StartObject.GridPosition == 1*GridCols+1 {
+				if ch.EndObject.Score.Value != 2 {
+					t.Errorf("Expected amplified miner output of value 2, got %d", ch.EndObject.Score.Value)
+				}
+				if len(ch.Effects) != 1 || ch.Effects[0] != EffectAmplifyValue {
+					t.Errorf("Expected change to record EffectAmplifyValue, got %v", ch.Effects)
+				}
+			}
+			if ch.Score != nil {
+				consumed += ch.Score.Value
+			}
+		}
+	}
+	if consumed != 6 {
+		t.Errorf("Expected consumer to score 6 from three amplified objects, got %d", consumed)
+	}
+	if len(miner.Effects) != 0 {
+		t.Errorf("Expected tick effects to expire by the end of the run, got %d", len(miner.Effects))
+	}
+}
+
+func TestSimulateRunLineage(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// The splitter sends red halves north and south. Processors turn the
+	// northern half blue, and both halves come back together into the sides
+	// of the combiner to be crafted into purple.
+	machines[cell(2, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 3)}, Orientation: OrientationEast}
+	machines[cell(2, 2)] = &MachineState{Machine: &Splitter{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Processor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Processor{}, Orientation: OrientationSouth}
+	machines[cell(3, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(3, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+	machines[cell(2, 3)] = &MachineState{Machine: &Combiner{}, Orientation: OrientationEast}
+	machines[cell(2, 4)] = &MachineState{Machine: &GeneralConsumer{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRun(machines, 1)
+	if err != nil {
+		t.Fatalf("SimulateRun failed: %v", err)
+	}
+
+	parents := make(map[int][]int)
+	var mined []int
+	var consumed []int
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.StartObject.ID == 0 {
+				t.Fatalf("Change has a start object without an ID: %+v", ch.StartObject)
+			}
+			if ch.StartObject.GridPosition == cell(2, 1) {
+				mined = append(mined, ch.StartObject.ID)
+			}
+			if ch.EndObject == nil {
+				if ch.Score != nil {
+					consumed = append(consumed, ch.StartObject.ID)
+				}
+				continue
+			}
+			if ch.EndObject.ID != ch.StartObject.ID {
+				parents[ch.EndObject.ID] = ch.EndObject.ParentIDs
+			}
+		}
+	}
+
+	// Walk each consumed object back to the object the miner produced.
+	root := func(id int) int {
+		for len(parents[id]) > 0 {
+			id = parents[id][0]
+		}
+		return id
+	}
+	if len(consumed) != len(mined) {
+		t.Fatalf("Expected one consumed object per mined object, got %d consumed and %d mined", len(consumed), len(mined))
+	}
+	for i, id := range consumed {
+		if got := root(id); got != mined[i] {
+			t.Errorf("Consumed object %d traces back to %d, expected mined object %d", id, got, mined[i])
+		}
+		if n := len(parents[id]); n != 2 {
+			t.Errorf("Expected consumed object %d to be combined from 2 parents, got %d", id, n)
+		}
+	}
+}
+
+func TestSimulateRunConveyorLoop(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Miner feeding a square of conveyors that loops back on itself.
+	machines[1*GridCols+1] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[1*GridCols+2] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[1*GridCols+3] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationSouth}
+	machines[2*GridCols+3] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationWest}
+	machines[2*GridCols+2] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+
+	changes, err := SimulateRun(machines, 1)
+	if !errors.Is(err, ErrInfiniteLoop) {
+		t.Fatalf("Expected ErrInfiniteLoop, got %v", err)
+	}
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("Expected a *RunError, got %T", err)
+	}
+	if runErr.Tick >= maxTicks {
+		t.Errorf("Expected the loop to be caught by a repeated state before the tick limit, got tick %d", runErr.Tick)
+	}
+	if len(runErr.Cells) == 0 {
+		t.Error("Expected the error to report the cells involved")
+	}
+	if len(changes) != runErr.Tick {
+		t.Errorf("Expected the changes up to the detected tick, got %d ticks for tick %d", len(changes), runErr.Tick)
+	}
+}
+
+func TestSimulateRunProcessorLoop(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// A red goes round a loop through a processor, which turns it into a new
+	// object of the next colour on every lap, and adds to its mult each time
+	// it is green.
+	machines[cell(1, 1)] = &MachineState{Machine: &source{types: repeat(t, "red", 1)}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &Processor{}, Orientation: OrientationSouth}
+	machines[cell(2, 3)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationWest}
+	machines[cell(2, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationNorth}
+
+	_, err := SimulateRun(machines, 1)
+	var runErr *RunError
+	if !errors.As(err, &runErr) || !errors.Is(err, ErrInfiniteLoop) {
+		t.Fatalf("Expected ErrInfiniteLoop, got %v", err)
+	}
+	// Three laps of four ticks bring the red back where it started.
+	if runErr.Tick > 20 {
+		t.Errorf("Expected the loop to be caught within a few laps, got tick %d", runErr.Tick)
+	}
+}
+
+// fountain is a test machine that drops a handful of new objects on its own
+// cell every tick.
+type fountain struct{}
+
+func (f *fountain) GetCapacity() int { return maxObjects * 2 }
+
+func (f *fountain) GetType() MachineType  { return MachineMiner }
+func (f *fountain) New() MachineInterface { return &fountain{} }
+func (f *fountain) GetInputs() []Side     { return nil }
+func (f *fountain) GetOutputs() []Side    { return nil }
+func (f *fountain) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	return nil
+}
+func (f *fountain) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	// Keep everything already here and add ten more.
+	var changes []*Change
+	for _, obj := range history[len(history)-1] {
+		if obj.GridPosition == position {
+			changes = append(changes, &Change{StartObject: obj, EndObject: obj})
+		}
+	}
+	for i := 0; i < 10; i++ {
+		changes = append(changes, &Change{
+			StartObject: &Object{GridPosition: position, Score: &Score{Value: 1, MultMult: 1}},
+			EndObject:   &Object{GridPosition: position, Score: &Score{Value: 1, MultMult: 1}},
+		})
+	}
+	return changes
+}
+
+func TestSimulateRunObjectExplosion(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[1*GridCols+1] = &MachineState{Machine: &fountain{}, Orientation: OrientationEast}
+
+	_, err := SimulateRun(machines, 1)
+	if !errors.Is(err, ErrObjectExplosion) {
+		t.Fatalf("Expected ErrObjectExplosion, got %v", err)
+	}
+	var runErr *RunError
+	if errors.As(err, &runErr) && (len(runErr.Cells) != 1 || runErr.Cells[0] != 1*GridCols+1) {
+		t.Errorf("Expected the explosion to be reported at the fountain's cell, got %v", runErr.Cells)
+	}
+}
+
+func TestGetAdjacentPositionDoesNotWrap(t *testing.T) {
+	endOfRow := 2*GridCols + GridCols - 1
+	if got := GetAdjacentPosition(endOfRow, OrientationEast); got != OffGrid {
+		t.Errorf("Expected moving east off the end of a row to leave the grid, got %d", got)
+	}
+	if got := GetAdjacentPosition(2*GridCols, OrientationWest); got != OffGrid {
+		t.Errorf("Expected moving west off the start of a row to leave the grid, got %d", got)
+	}
+	if got := GetAdjacentPosition(1, OrientationNorth); got != OffGrid {
+		t.Errorf("Expected moving north off the top row to leave the grid, got %d", got)
+	}
+}
+
+func TestSimulateRunFates(t *testing.T) {
+	tests := []struct {
+		name     string
+		minerPos int
+		facing   Orientation
+		want     Fate
+	}{
+		{"spilled onto the border", 1*GridCols + GridCols - 2, OrientationEast, FateSpilled},
+		{"lost off the end of a row", 1*GridCols + GridCols - 1, OrientationEast, FateLost},
+		{"waiting on an empty floor cell", 1*GridCols + 1, OrientationSouth, FateWaiting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machines := make([]*MachineState, GridCols*GridRows)
+			machines[tt.minerPos] = &MachineState{Machine: &Miner{}, Orientation: tt.facing}
+
+			changes, err := SimulateRun(machines, 1)
+			if err != nil {
+				t.Fatalf("SimulateRun failed: %v", err)
+			}
+			if len(changes) != 3 {
+				t.Fatalf("Expected objects to leave play as soon as they land, got %d ticks", len(changes))
+			}
+			for _, tickChanges := range changes {
+				for _, ch := range tickChanges {
+					if ch.Fate != tt.want {
+						t.Errorf("Expected %s, got %s", FateName(tt.want), FateName(ch.Fate))
+					}
+					penalty := 0
+					if ch.Score != nil {
+						penalty = -ch.Score.Value
+					}
+					if penalty != FatePenalties[tt.want] {
+						t.Errorf("Expected a penalty of %d, got %d", FatePenalties[tt.want], penalty)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateRunWithHand(t *testing.T) {
+	red, _ := ObjectTypeByKey("red")
+	blue, _ := ObjectTypeByKey("blue")
+	ore, _ := ObjectTypeByKey("ore")
+	hand := []*Object{
+		{Type: red, Score: &Score{Value: 1, MultMult: 1}},
+		{Type: blue, Edition: EditionFoil, Score: &Score{Value: 3, MultMult: 1}},
+		{Type: ore, Score: &Score{Value: 1, MultMult: 1}},
+	}
+	machines := make([]*MachineState, GridCols*GridRows)
+	// The first miner in grid order is dealt the first and third objects,
+	// the second miner the second.
+	first := &Miner{}
+	second := &Miner{}
+	machines[cell(1, 1)] = &MachineState{Machine: first, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(3, 1)] = &MachineState{Machine: second, Orientation: OrientationEast}
+	machines[cell(3, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRunWith(machines, 1, RunOptions{Hand: hand})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+
+	emitted := make(map[int][]*Object)
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if pos := ch.StartObject.GridPosition; pos == cell(1, 1) || pos == cell(3, 1) {
+				emitted[pos] = append(emitted[pos], ch.StartObject)
+			}
+		}
+	}
+	want := map[int][]*Object{cell(1, 1): {hand[0], hand[2]}, cell(3, 1): {hand[1]}}
+	for pos, objects := range want {
+		if len(emitted[pos]) != len(objects) {
+			t.Fatalf("Expected the miner at %d to emit %d objects, got %d", pos, len(objects), len(emitted[pos]))
+		}
+		for i, obj := range objects {
+			got := emitted[pos][i]
+			if got.Type != obj.Type || got.Edition != obj.Edition || got.Score.Value != obj.Score.Value {
+				t.Errorf("Expected the miner at %d to emit %+v, got %+v", pos, obj, got)
+			}
+		}
+	}
+	if got := emitted[cell(1, 1)][0]; got == hand[0] || got.Score == hand[0].Score {
+		t.Error("Expected the miner to emit a copy of the hand's object")
+	}
+	if first.feed != nil || second.feed != nil {
+		t.Error("Expected the miners' feeds to be cleared after the run")
+	}
+
+	changes, err = SimulateRunWith(machines, 1, RunOptions{Hand: []*Object{}})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected an empty hand to feed nothing, got %d ticks", len(changes))
+	}
+}
+
+func TestSimulateRunWithHandBlocked(t *testing.T) {
+	red, _ := ObjectTypeByKey("red")
+	var hand []*Object
+	for i := 0; i < 4; i++ {
+		hand = append(hand, &Object{Type: red, Score: &Score{Value: 1, MultMult: 1}})
+	}
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Two miners feed the same conveyor, so the second is blocked whenever
+	// both emit on the same tick.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &Conveyor{}, Orientation: OrientationEast}
+	machines[cell(1, 3)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 2)] = &MachineState{Machine: &Miner{}, Orientation: OrientationNorth}
+
+	changes, err := SimulateRunWith(machines, 1, RunOptions{Hand: hand})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+
+	consumed := 0
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if Consumed(ch) {
+				consumed++
+			}
+		}
+	}
+	if consumed != len(hand) {
+		t.Errorf("Expected all %d objects in the hand to be consumed, got %d", len(hand), consumed)
+	}
+}
+
+func TestSimulateRunWithHandNoScore(t *testing.T) {
+	blue, _ := ObjectTypeByKey("blue")
+	hand := []*Object{{Type: blue}}
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	changes, err := SimulateRunWith(machines, 1, RunOptions{Hand: hand})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+	want := ObjectTypeOf(blue).BaseValue
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.StartObject.GridPosition != cell(1, 1) {
+				continue
+			}
+			if got := ch.StartObject.Score; got == nil || got.Value != want || got.MultMult != 1 {
+				t.Errorf("Expected an object worth the base value %d, got %+v", want, got)
+			}
+			return
+		}
+	}
+	t.Error("Expected the miner to emit the hand object")
+}
@@ -0,0 +1,57 @@
+package sim
+
+import "math/big"
+
+// RunTotal is a run's score as it stands: the round score and the multiplier
+// it is multiplied by. Multipliers compound quickly, so both are held to
+// arbitrary precision.
+type RunTotal struct {
+	RoundScore *big.Int
+	Multiplier *big.Int
+}
+
+// Points returns what the run adds to the player's total score.
+func (t RunTotal) Points() *big.Int {
+	return new(big.Int).Mul(t.RoundScore, t.Multiplier)
+}
+
+// ScoreRun works out a run's total from its changes. Every change that
+// carries a Score is scored: consumed objects, and the penalties for objects
+// that were spilled or lost. Scoring goes through fixed phases, each taken
+// over every scored change before the next begins, so the total does not
+// depend on the order the changes happened in:
+//
+//  1. Base value: each change's Value is added to the round score, which
+//     starts at 0.
+//  2. Additive mult: each change's MultAdd is added to the multiplier, which
+//     starts at 1.
+//  3. Multiplicative mult: the multiplier is multiplied by each change's
+//     MultMult.
+//  4. Foremen: each foreman's OnRunEnd changes the total, in the order the
+//     foremen are held (see EndRun).
+//
+// An object's edition is already part of its Score when it is consumed (see
+// Object.FinalScore), and foremen that act on changes have already had their
+// say during the run. The run is worth the total's Points.
+func ScoreRun(changes [][]*Change, foremen []Foreman) RunTotal {
+	var scores []*Score
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if ch.Score != nil {
+				scores = append(scores, ch.Score)
+			}
+		}
+	}
+	total := RunTotal{RoundScore: big.NewInt(0), Multiplier: big.NewInt(1)}
+	for _, s := range scores {
+		total.RoundScore.Add(total.RoundScore, big.NewInt(int64(s.Value)))
+	}
+	for _, s := range scores {
+		total.Multiplier.Add(total.Multiplier, big.NewInt(int64(s.MultAdd)))
+	}
+	for _, s := range scores {
+		total.Multiplier.Mul(total.Multiplier, big.NewInt(int64(s.MultMult)))
+	}
+	EndRun(foremen, changes, &total)
+	return total
+}
@@ -0,0 +1,136 @@
+package sim
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestScoreRun(t *testing.T) {
+	consume := func(value, multAdd, multMult int) *Change {
+		return &Change{StartObject: &Object{}, Score: &Score{Value: value, MultAdd: multAdd, MultMult: multMult}, Payout: 1}
+	}
+	tests := []struct {
+		name    string
+		changes [][]*Change
+		foremen []Foreman
+		score   int64
+		mult    int64
+	}{
+		{"nothing scored", nil, nil, 0, 1},
+		{"values add", [][]*Change{{consume(2, 0, 1)}, {consume(3, 0, 1)}}, nil, 5, 1},
+		// The x2 comes before the +3 but is applied after it: (1+3)*2.
+		{"add before multiply", [][]*Change{{consume(1, 0, 2)}, {consume(1, 3, 1)}}, nil, 2, 8},
+		{"multiply after add", [][]*Change{{consume(1, 3, 1)}, {consume(1, 0, 2)}}, nil, 2, 8},
+		{"penalty", [][]*Change{{consume(4, 0, 1), {StartObject: &Object{}, EndObject: &Object{}, Fate: FateSpilled, Score: &Score{Value: -1, MultMult: 1}}}}, nil, 3, 1},
+		{"foremen last", [][]*Change{{consume(1, 1, 1)}}, []Foreman{&UnionRep{}}, 1, 5},
+	}
+	for _, tt := range tests {
+		got := ScoreRun(tt.changes, tt.foremen)
+		if got.RoundScore.Int64() != tt.score || got.Multiplier.Int64() != tt.mult {
+			t.Errorf("%s: Expected %d x %d, got %v x %v", tt.name, tt.score, tt.mult, got.RoundScore, got.Multiplier)
+		}
+	}
+}
+
+func TestScoreRunDoesNotOverflow(t *testing.T) {
+	// Seventy objects each doubling the multiplier take it well past what an
+	// int can hold.
+	var changes [][]*Change
+	for i := 0; i < 70; i++ {
+		changes = append(changes, []*Change{{StartObject: &Object{}, Score: &Score{Value: 1, MultMult: 2}}})
+	}
+	total := ScoreRun(changes, nil)
+	want := new(big.Int).Lsh(big.NewInt(70), 70)
+	if got := total.Points(); got.Cmp(want) != 0 {
+		t.Errorf("Expected 70 x 2^70 points, got %v", got)
+	}
+}
+
+func TestScoreRunPoints(t *testing.T) {
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Two reds and a foil blue go straight into a consumer: the foil adds 5
+	// to the blue's value, and the blue collar foreman adds +2 mult.
+	red, _ := ObjectTypeByKey("red")
+	blue, _ := ObjectTypeByKey("blue")
+	hand := []*Object{
+		{Type: red, Score: &Score{Value: 1, MultMult: 1}},
+		{Type: red, Score: &Score{Value: 1, MultMult: 1}},
+		{Type: blue, Edition: EditionFoil, Score: &Score{Value: 1, MultMult: 1}},
+	}
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(1, 2)] = &MachineState{Machine: &GeneralConsumer{}}
+
+	foremen := []Foreman{&BlueCollar{}}
+	changes, err := SimulateRunWith(machines, 1, RunOptions{Hand: hand, Foremen: foremen})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+	total := ScoreRun(changes, foremen)
+	if total.RoundScore.Int64() != 8 || total.Multiplier.Int64() != 3 {
+		t.Errorf("Expected a round score of 8 and a multiplier of 3, got %v x %v", total.RoundScore, total.Multiplier)
+	}
+	if got := total.Points().Int64(); got != 24 {
+		t.Errorf("Expected 24 points, got %d", got)
+	}
+}
+
+func TestSaturating(t *testing.T) {
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"add", saturatingAdd(2, 3), 5},
+		{"add past max", saturatingAdd(math.MaxInt, 1), math.MaxInt},
+		{"add past min", saturatingAdd(math.MinInt, -1), math.MinInt},
+		{"sub", saturatingSub(2, 3), -1},
+		{"sub past max", saturatingSub(math.MaxInt, -1), math.MaxInt},
+		{"sub past min", saturatingSub(0, math.MinInt), math.MaxInt},
+		{"mul", saturatingMul(-4, 3), -12},
+		{"mul past max", saturatingMul(math.MaxInt/2+1, 2), math.MaxInt},
+		{"mul past min", saturatingMul(math.MaxInt/2+1, -3), math.MinInt},
+		{"negate min", saturatingMul(math.MinInt, -1), math.MaxInt},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: Expected %d, got %d", tt.name, tt.want, tt.got)
+		}
+	}
+}
+
+func TestAmplifiersSaturate(t *testing.T) {
+	red, _ := ObjectTypeByKey("red")
+	hand := []*Object{{Type: red, Score: &Score{Value: math.MaxInt / 4, MultMult: math.MaxInt/2 + 1}}}
+	machines := make([]*MachineState, GridCols*GridRows)
+	// Three amplifiers, the last retriggered, double the value past the
+	// largest int, and the polisher doubles the mult past it too.
+	machines[cell(1, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	for col := 2; col <= 4; col++ {
+		machines[cell(1, col)] = &MachineState{Machine: &Amplifier{}, Orientation: OrientationEast}
+	}
+	machines[cell(1, 5)] = &MachineState{Machine: &GeneralConsumer{}}
+	machines[cell(2, 4)] = &MachineState{Machine: &Repeater{}, Orientation: OrientationEast}
+	machines[cell(0, 3)] = &MachineState{Machine: &Polisher{}, Orientation: OrientationEast}
+
+	changes, err := SimulateRunWith(machines, 1, RunOptions{Hand: hand})
+	if err != nil {
+		t.Fatalf("SimulateRunWith failed: %v", err)
+	}
+	var scores []Score
+	for _, tickChanges := range changes {
+		for _, ch := range tickChanges {
+			if Consumed(ch) {
+				scores = append(scores, *ch.Score)
+			}
+		}
+	}
+	want := Score{Value: math.MaxInt, MultMult: math.MaxInt}
+	if len(scores) != 1 || scores[0] != want {
+		t.Fatalf("Expected one object held at the largest int, got %+v", scores)
+	}
+	total := ScoreRun(changes, nil)
+	if total.RoundScore.Cmp(big.NewInt(math.MaxInt64)) != 0 || total.Multiplier.Cmp(big.NewInt(math.MaxInt64)) != 0 {
+		t.Errorf("Expected a round score and multiplier of %d, got %v x %v", int64(math.MaxInt64), total.RoundScore, total.Multiplier)
+	}
+}
@@ -0,0 +1,791 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxScriptSteps is the most statements and expressions a machine's script
+// may evaluate in one tick. A script that runs past it is stopped and its
+// machine does nothing that tick, so a script cannot hang SimulateRun.
+const maxScriptSteps = 10000
+
+// ErrStepLimit is returned when a script runs for more than maxScriptSteps.
+var ErrStepLimit = errors.New("script step limit reached")
+
+// A Script is a small program that decides what a machine does with each
+// object on its cell. Scripts can only read and change the object, read the
+// tick, the machine's facing and how many objects sit on the neighbouring
+// cells, and say where the object goes, so they cannot reach anything outside
+// the simulation.
+//
+// A script is a list of statements, one per line or separated by semicolons:
+//
+//	set NAME = EXPR              change the object or a local variable
+//	if EXPR then ... [else ...] end
+//	while EXPR do ... end
+//	out SIDE                     send a copy of the object out of a side
+//	consume                      score the object, pay $1 and stop
+//
+// The object is read and changed through type, value, mult (its additive
+// multiplier) and times (its multiplicative multiplier). The read-only names
+// tick and facing give the tick and the machine's orientation, the key of
+// every object type, such as red or ore, names that type, and front, right,
+// back and left name the machine's sides. Expressions are integers, with + - * / %, comparisons, and, or, not,
+// and the functions objects(SIDE), the number of objects on the cell beyond
+// that side, min(A, B), max(A, B) and random(N), a number from 0 to N-1.
+// Comparisons and logic give 1 for true and 0 for false. + - and * hold at the
+// largest or smallest integer rather than overflowing.
+//
+// An object the script neither sends out nor consumes stays where it is.
+type Script struct {
+	body []scriptStmt
+}
+
+// ParseScript parses the source of a script.
+func ParseScript(source string) (*Script, error) {
+	tokens, err := lexScript(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &scriptParser{tokens: tokens}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return &Script{body: body}, nil
+}
+
+// Outputs returns the sides the script may send objects out of, as far as can
+// be told without running it: every side named directly by an out statement,
+// or all four if any out statement works its side out.
+func (s *Script) Outputs() []Side {
+	seen := make(map[Side]bool)
+	walkScript(s.body, func(st scriptStmt) {
+		out, ok := st.(*outStmt)
+		if !ok {
+			return
+		}
+		if name, ok := out.side.(*nameExpr); ok {
+			if side, ok := scriptSides[string(*name)]; ok {
+				seen[side] = true
+				return
+			}
+		}
+		for side := SideFront; side <= SideLeft; side++ {
+			seen[side] = true
+		}
+	})
+	var sides []Side
+	for side := SideFront; side <= SideLeft; side++ {
+		if seen[side] {
+			sides = append(sides, side)
+		}
+	}
+	return sides
+}
+
+// uses reports whether any statement in the script is of the given kind.
+func (s *Script) uses(match func(scriptStmt) bool) bool {
+	found := false
+	walkScript(s.body, func(st scriptStmt) {
+		if match(st) {
+			found = true
+		}
+	})
+	return found
+}
+
+// Run runs the script on one object on a machine's cell and returns the
+// changes it makes. It returns an error, and no changes, if the script fails
+// or runs past maxScriptSteps.
+func (s *Script) Run(obj *Object, position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand) ([]*Change, error) {
+	run := &scriptRun{
+		vars: map[string]int{
+			"type":  int(obj.Type),
+			"value": obj.Score.Value,
+			"mult":  obj.Score.MultAdd,
+			"times": obj.Score.MultMult,
+		},
+		position:    position,
+		current:     history[len(history)-1],
+		tick:        tick,
+		orientation: orientation,
+		rng:         rng,
+	}
+	if err := run.block(s.body); err != nil && !errors.Is(err, errScriptStop) {
+		return nil, err
+	}
+
+	score := &Score{Value: run.vars["value"], MultAdd: run.vars["mult"], MultMult: run.vars["times"]}
+	if run.consumed {
+		scored := &Object{Score: score, Edition: obj.Edition}
+		return []*Change{{StartObject: obj, Score: scored.FinalScore(), Payout: 1}}, nil
+	}
+	objType := ObjectType(run.vars["type"])
+	// Only an object that goes out unchanged in type and in one piece keeps
+	// its ID.
+	sameObject := len(run.outs) == 1 && objType == obj.Type
+	var changes []*Change
+	for _, side := range run.outs {
+		end := &Object{
+			GridPosition: GetAdjacentPosition(position, side.Facing(orientation)),
+			Type:         objType,
+			Edition:      obj.Edition,
+			Score:        &Score{Value: score.Value, MultAdd: score.MultAdd, MultMult: score.MultMult},
+		}
+		if sameObject {
+			end.ID = obj.ID
+		} else {
+			end.ParentIDs = []int{obj.ID}
+		}
+		changes = append(changes, &Change{StartObject: obj, EndObject: end})
+	}
+	return changes, nil
+}
+
+// scriptSides are the names of a machine's sides in scripts.
+var scriptSides = map[string]Side{
+	"front": SideFront,
+	"right": SideRight,
+	"back":  SideBack,
+	"left":  SideLeft,
+}
+
+// scriptKeywords cannot be used as variable names.
+var scriptKeywords = map[string]bool{
+	"set": true, "if": true, "then": true, "else": true, "end": true, "while": true,
+	"do": true, "out": true, "consume": true, "and": true, "or": true, "not": true,
+}
+
+// errScriptStop ends a script early once the object has been consumed.
+var errScriptStop = errors.New("script stopped")
+
+// scriptRun is the state of one run of a script.
+type scriptRun struct {
+	vars        map[string]int
+	steps       int
+	position    int
+	current     []*Object
+	tick        int
+	orientation Orientation
+	rng         *rand.Rand
+	outs        []Side
+	consumed    bool
+}
+
+// step counts one statement or expression towards maxScriptSteps.
+func (r *scriptRun) step() error {
+	r.steps++
+	if r.steps > maxScriptSteps {
+		return ErrStepLimit
+	}
+	return nil
+}
+
+func (r *scriptRun) block(body []scriptStmt) error {
+	for _, st := range body {
+		if err := r.step(); err != nil {
+			return err
+		}
+		if err := st.exec(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a name.
+func (r *scriptRun) lookup(name string) (int, error) {
+	if v, ok := r.vars[name]; ok {
+		return v, nil
+	}
+	if side, ok := scriptSides[name]; ok {
+		return int(side), nil
+	}
+	if t, ok := ObjectTypeByKey(name); ok {
+		return int(t), nil
+	}
+	switch name {
+	case "tick":
+		return r.tick, nil
+	case "facing":
+		return int(r.orientation), nil
+	}
+	return 0, fmt.Errorf("unknown name %q", name)
+}
+
+// side returns the side an expression names.
+func (r *scriptRun) side(e scriptExpr) (Side, error) {
+	v, err := e.eval(r)
+	if err != nil {
+		return 0, err
+	}
+	if v < int(SideFront) || v > int(SideLeft) {
+		return 0, fmt.Errorf("%d is not a side", v)
+	}
+	return Side(v), nil
+}
+
+// Statements
+
+type scriptStmt interface {
+	exec(r *scriptRun) error
+}
+
+type setStmt struct {
+	name  string
+	value scriptExpr
+}
+
+func (s *setStmt) exec(r *scriptRun) error {
+	v, err := s.value.eval(r)
+	if err != nil {
+		return err
+	}
+	if s.name == "type" && ObjectTypeOf(ObjectType(v)) == unknownObjectType {
+		return fmt.Errorf("%d is not an object type", v)
+	}
+	r.vars[s.name] = v
+	return nil
+}
+
+type ifStmt struct {
+	cond      scriptExpr
+	then, els []scriptStmt
+}
+
+func (s *ifStmt) exec(r *scriptRun) error {
+	v, err := s.cond.eval(r)
+	if err != nil {
+		return err
+	}
+	if v != 0 {
+		return r.block(s.then)
+	}
+	return r.block(s.els)
+}
+
+type whileStmt struct {
+	cond scriptExpr
+	body []scriptStmt
+}
+
+func (s *whileStmt) exec(r *scriptRun) error {
+	for {
+		v, err := s.cond.eval(r)
+		if err != nil {
+			return err
+		}
+		if v == 0 {
+			return nil
+		}
+		if err := r.block(s.body); err != nil {
+			return err
+		}
+	}
+}
+
+type outStmt struct {
+	side scriptExpr
+}
+
+func (s *outStmt) exec(r *scriptRun) error {
+	side, err := r.side(s.side)
+	if err != nil {
+		return err
+	}
+	r.outs = append(r.outs, side)
+	return nil
+}
+
+type consumeStmt struct{}
+
+func (s *consumeStmt) exec(r *scriptRun) error {
+	r.consumed = true
+	return errScriptStop
+}
+
+// walkScript calls visit on every statement, including those nested in ifs
+// and loops.
+func walkScript(body []scriptStmt, visit func(scriptStmt)) {
+	for _, st := range body {
+		visit(st)
+		switch s := st.(type) {
+		case *ifStmt:
+			walkScript(s.then, visit)
+			walkScript(s.els, visit)
+		case *whileStmt:
+			walkScript(s.body, visit)
+		}
+	}
+}
+
+// Expressions
+
+type scriptExpr interface {
+	eval(r *scriptRun) (int, error)
+}
+
+type numberExpr int
+
+func (e numberExpr) eval(r *scriptRun) (int, error) {
+	return int(e), r.step()
+}
+
+type nameExpr string
+
+func (e *nameExpr) eval(r *scriptRun) (int, error) {
+	if err := r.step(); err != nil {
+		return 0, err
+	}
+	return r.lookup(string(*e))
+}
+
+type unaryExpr struct {
+	op string
+	x  scriptExpr
+}
+
+func (e *unaryExpr) eval(r *scriptRun) (int, error) {
+	if err := r.step(); err != nil {
+		return 0, err
+	}
+	x, err := e.x.eval(r)
+	if err != nil {
+		return 0, err
+	}
+	if e.op == "-" {
+		return -x, nil
+	}
+	return truth(x == 0), nil
+}
+
+type binaryExpr struct {
+	op   string
+	l, r scriptExpr
+}
+
+func (e *binaryExpr) eval(r *scriptRun) (int, error) {
+	if err := r.step(); err != nil {
+		return 0, err
+	}
+	l, err := e.l.eval(r)
+	if err != nil {
+		return 0, err
+	}
+	// and and or only look at the right-hand side when they need to.
+	switch {
+	case e.op == "and" && l == 0:
+		return 0, nil
+	case e.op == "or" && l != 0:
+		return 1, nil
+	}
+	rv, err := e.r.eval(r)
+	if err != nil {
+		return 0, err
+	}
+	switch e.op {
+	case "+":
+		return saturatingAdd(l, rv), nil
+	case "-":
+		return saturatingSub(l, rv), nil
+	case "*":
+		return saturatingMul(l, rv), nil
+	case "/", "%":
+		if rv == 0 {
+			return 0, errors.New("division by zero")
+		}
+		if e.op == "/" {
+			return l / rv, nil
+		}
+		return l % rv, nil
+	case "==":
+		return truth(l == rv), nil
+	case "!=":
+		return truth(l != rv), nil
+	case "<":
+		return truth(l < rv), nil
+	case "<=":
+		return truth(l <= rv), nil
+	case ">":
+		return truth(l > rv), nil
+	case ">=":
+		return truth(l >= rv), nil
+	default: // and, or
+		return truth(rv != 0), nil
+	}
+}
+
+type callExpr struct {
+	name string
+	args []scriptExpr
+}
+
+// scriptFunctions are the functions a script may call, by number of
+// arguments.
+var scriptFunctions = map[string]int{
+	"objects": 1,
+	"min":     2,
+	"max":     2,
+	"random":  1,
+}
+
+func (e *callExpr) eval(r *scriptRun) (int, error) {
+	if err := r.step(); err != nil {
+		return 0, err
+	}
+	if e.name == "objects" {
+		side, err := r.side(e.args[0])
+		if err != nil {
+			return 0, err
+		}
+		cell := GetAdjacentPosition(r.position, side.Facing(r.orientation))
+		count := 0
+		for _, obj := range r.current {
+			if obj.GridPosition == cell {
+				count++
+			}
+		}
+		return count, nil
+	}
+	args := make([]int, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(r)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	switch e.name {
+	case "min":
+		return min(args[0], args[1]), nil
+	case "max":
+		return max(args[0], args[1]), nil
+	default: // random
+		if args[0] <= 0 {
+			return 0, fmt.Errorf("random(%d) needs a positive number", args[0])
+		}
+		return r.rng.Intn(args[0]), nil
+	}
+}
+
+func truth(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNewline
+	tokenNumber
+	tokenName
+	tokenSymbol
+)
+
+type scriptToken struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lexScript splits a script into tokens. Semicolons count as newlines and
+// # starts a comment that runs to the end of the line.
+func lexScript(source string) ([]scriptToken, error) {
+	var tokens []scriptToken
+	line := 1
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n' || c == ';':
+			tokens = append(tokens, scriptToken{kind: tokenNewline, text: string(c), line: line})
+			if c == '\n' {
+				line++
+			}
+			i++
+		case c == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, scriptToken{kind: tokenNumber, text: string(runes[start:i]), line: line})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, scriptToken{kind: tokenName, text: string(runes[start:i]), line: line})
+		default:
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); two == "==" || two == "!=" || two == "<=" || two == ">=" {
+					tokens = append(tokens, scriptToken{kind: tokenSymbol, text: two, line: line})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%<>=(),", c) {
+				return nil, fmt.Errorf("line %d: unexpected %q", line, c)
+			}
+			tokens = append(tokens, scriptToken{kind: tokenSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	return append(tokens, scriptToken{kind: tokenEOF, line: line}), nil
+}
+
+// Parser
+
+type scriptParser struct {
+	tokens []scriptToken
+	pos    int
+}
+
+func (p *scriptParser) peek() scriptToken {
+	return p.tokens[p.pos]
+}
+
+func (p *scriptParser) next() scriptToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the given word or symbol.
+func (p *scriptParser) accept(text string) bool {
+	if tok := p.peek(); (tok.kind == tokenName || tok.kind == tokenSymbol) && tok.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *scriptParser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		return p.errorf(tok, "expected %q, got %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *scriptParser) errorf(tok scriptToken, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
+
+// block parses statements up to the end of the script or the end or else that
+// closes the block.
+func (p *scriptParser) block() ([]scriptStmt, error) {
+	var body []scriptStmt
+	for {
+		for p.peek().kind == tokenNewline {
+			p.next()
+		}
+		tok := p.peek()
+		if tok.kind == tokenEOF || (tok.kind == tokenName && (tok.text == "end" || tok.text == "else")) {
+			return body, nil
+		}
+		st, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, st)
+		if tok := p.peek(); tok.kind != tokenNewline && tok.kind != tokenEOF && !(tok.kind == tokenName && (tok.text == "end" || tok.text == "else")) {
+			return nil, p.errorf(tok, "expected the end of the statement, got %q", tok.text)
+		}
+	}
+}
+
+func (p *scriptParser) statement() (scriptStmt, error) {
+	tok := p.next()
+	if tok.kind != tokenName {
+		return nil, p.errorf(tok, "expected a statement, got %q", tok.text)
+	}
+	switch tok.text {
+	case "set":
+		name := p.next()
+		if name.kind != tokenName || scriptKeywords[name.text] {
+			return nil, p.errorf(name, "expected a name to set, got %q", name.text)
+		}
+		if _, err := (&scriptRun{}).lookup(name.text); err == nil {
+			return nil, p.errorf(name, "%q cannot be changed", name.text)
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &setStmt{name: name.text, value: value}, nil
+	case "if":
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		then, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		var els []scriptStmt
+		if p.accept("else") {
+			if els, err = p.block(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("end"); err != nil {
+			return nil, err
+		}
+		return &ifStmt{cond: cond, then: then, els: els}, nil
+	case "while":
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("end"); err != nil {
+			return nil, err
+		}
+		return &whileStmt{cond: cond, body: body}, nil
+	case "out":
+		side, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return &outStmt{side: side}, nil
+	case "consume":
+		return &consumeStmt{}, nil
+	default:
+		return nil, p.errorf(tok, "unknown statement %q", tok.text)
+	}
+}
+
+// Expressions are parsed by precedence, loosest first: or, and, not,
+// comparisons, + and -, then * / and %.
+func (p *scriptParser) expr() (scriptExpr, error) {
+	return p.binary(0)
+}
+
+var scriptPrecedence = [][]string{
+	{"or"},
+	{"and"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *scriptParser) binary(level int) (scriptExpr, error) {
+	if level == len(scriptPrecedence) {
+		return p.unary()
+	}
+	if level == 2 && p.accept("not") {
+		x, err := p.binary(level)
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "not", x: x}, nil
+	}
+	l, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, candidate := range scriptPrecedence[level] {
+			if p.accept(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			return l, nil
+		}
+		r, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryExpr{op: op, l: l, r: r}
+	}
+}
+
+func (p *scriptParser) unary() (scriptExpr, error) {
+	if p.accept("-") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "-", x: x}, nil
+	}
+	tok := p.next()
+	switch {
+	case tok.kind == tokenNumber:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, p.errorf(tok, "bad number %q", tok.text)
+		}
+		return numberExpr(n), nil
+	case tok.kind == tokenSymbol && tok.text == "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case tok.kind == tokenName && !scriptKeywords[tok.text]:
+		if !p.accept("(") {
+			name := nameExpr(tok.text)
+			return &name, nil
+		}
+		arity, ok := scriptFunctions[tok.text]
+		if !ok {
+			return nil, p.errorf(tok, "unknown function %q", tok.text)
+		}
+		var args []scriptExpr
+		for !p.accept(")") {
+			if len(args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		if len(args) != arity {
+			return nil, p.errorf(tok, "%s takes %d arguments, got %d", tok.text, arity, len(args))
+		}
+		return &callExpr{name: tok.text, args: args}, nil
+	default:
+		return nil, p.errorf(tok, "expected a value, got %q", tok.text)
+	}
+}
@@ -0,0 +1,157 @@
+package sim
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"out front\nteleport", `line 2: unknown statement "teleport"`},
+		{"set tick = 3", `line 1: "tick" cannot be changed`},
+		{"if value > 1 then out front", `expected "end"`},
+		{"set value = value +", `expected a value`},
+		{"set value = sqrt(value)", `unknown function "sqrt"`},
+		{"set value = min(1)", `min takes 2 arguments, got 1`},
+		{"out front out back", `expected the end of the statement`},
+		{"set value = 1 & 2", `unexpected '&'`},
+	}
+	for _, tt := range tests {
+		_, err := ParseScript(tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected %q to fail with %q, got %v", tt.source, tt.want, err)
+		}
+	}
+}
+
+func TestScriptRun(t *testing.T) {
+	position := cell(2, 2)
+	tests := []struct {
+		name    string
+		source  string
+		objType ObjectType
+		tick    int
+		ends    []int
+		value   int
+		mult    int
+		endType ObjectType
+		keepID  bool
+	}{
+		{"sort red left", "if type == red then out left else out front end", ObjectRed, 0, []int{cell(1, 2)}, 1, 0, ObjectRed, true},
+		{"sort blue on", "if type == red then out left else out front end", ObjectBlue, 0, []int{cell(2, 3)}, 1, 0, ObjectBlue, true},
+		{"upgrade", "set value = value * 3 + 1; set mult = mult + 2; out front", ObjectRed, 0, []int{cell(2, 3)}, 4, 2, ObjectRed, true},
+		{"transform", "set type = (type + 1) % 3\nout front", ObjectBlue, 0, []int{cell(2, 3)}, 1, 0, ObjectRed, false},
+		{"split", "set value = max(value / 2, 1)\nout left\nout right", ObjectGreen, 0, []int{cell(1, 2), cell(3, 2)}, 1, 0, ObjectGreen, false},
+		{"even ticks", "if tick % 2 == 0 then out front end", ObjectRed, 1, nil, 0, 0, 0, false},
+		{"loop", "set n = 0\nwhile n < 5 do\n  set n = n + 1\n  set value = value + n\nend\nout front", ObjectRed, 0, []int{cell(2, 3)}, 16, 0, ObjectRed, true},
+		{"blocked ahead", "if objects(front) == 0 then out front else out right end", ObjectRed, 0, []int{cell(3, 2)}, 1, 0, ObjectRed, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := ParseScript(tt.source)
+			if err != nil {
+				t.Fatalf("ParseScript failed: %v", err)
+			}
+			obj := &Object{ID: 7, GridPosition: position, Type: tt.objType, Score: &Score{Value: 1, MultMult: 1}}
+			ahead := &Object{ID: 8, GridPosition: cell(2, 3), Score: &Score{Value: 1, MultMult: 1}}
+			history := [][]*Object{{obj}}
+			if tt.name == "blocked ahead" {
+				history = [][]*Object{{obj, ahead}}
+			}
+			changes, err := script.Run(obj, position, history, tt.tick, OrientationEast, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if len(changes) != len(tt.ends) {
+				t.Fatalf("Expected %d changes, got %d", len(tt.ends), len(changes))
+			}
+			for i, ch := range changes {
+				end := ch.EndObject
+				if end.GridPosition != tt.ends[i] || end.Score.Value != tt.value || end.Score.MultAdd != tt.mult || end.Type != tt.endType {
+					t.Errorf("Expected object at %d worth %d, +%d mult, type %d, got %d worth %d, +%d mult, type %d",
+						tt.ends[i], tt.value, tt.mult, tt.endType, end.GridPosition, end.Score.Value, end.Score.MultAdd, end.Type)
+				}
+				if (end.ID == obj.ID) != tt.keepID {
+					t.Errorf("Expected keeping the ID to be %v, got ID %d", tt.keepID, end.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestScriptConsume(t *testing.T) {
+	script, err := ParseScript("set value = value + 4\nconsume\nout front")
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	obj := &Object{ID: 1, GridPosition: cell(2, 2), Score: &Score{Value: 1, MultMult: 1}}
+	changes, err := script.Run(obj, cell(2, 2), [][]*Object{{obj}}, 0, OrientationEast, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].EndObject != nil || changes[0].Score.Value != 5 || changes[0].Payout != 1 {
+		t.Errorf("Expected the object to be consumed for 5, got %+v", changes)
+	}
+	if len(script.Outputs()) != 1 {
+		t.Errorf("Expected the unreachable out to still count as an output, got %v", script.Outputs())
+	}
+}
+
+func TestScriptStepLimit(t *testing.T) {
+	script, err := ParseScript("while 1 do set value = value + 1 end\nout front")
+	if err != nil {
+		t.Fatalf("ParseScript failed: %v", err)
+	}
+	obj := &Object{ID: 1, GridPosition: cell(2, 2), Score: &Score{Value: 1, MultMult: 1}}
+	if _, err := script.Run(obj, cell(2, 2), [][]*Object{{obj}}, 0, OrientationEast, nil); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("Expected ErrStepLimit, got %v", err)
+	}
+
+	// A machine whose script never finishes just holds on to its object, and
+	// the run still ends, reporting the step limit.
+	machines := make([]*MachineState, GridCols*GridRows)
+	machines[cell(2, 1)] = &MachineState{Machine: &Miner{}, Orientation: OrientationEast}
+	machines[cell(2, 2)] = &MachineState{Machine: &ScriptMachine{machineType: firstModType, script: script, inputs: []Side{SideBack}, outputs: []Side{SideFront}}, Orientation: OrientationEast}
+	_, err = SimulateRun(machines, 1)
+	var runErr *RunError
+	if errors.As(err, &runErr) {
+		t.Errorf("Expected the run to end, got %v", err)
+	}
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) || !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("Expected a *ScriptError wrapping ErrStepLimit, got %v", err)
+	}
+	if scriptErr.Position != cell(2, 2) {
+		t.Errorf("Expected the script at %d to be blamed, got %d", cell(2, 2), scriptErr.Position)
+	}
+}
+
+func TestLoadScriptedMod(t *testing.T) {
+	dir := writeMods(t, map[string]string{
+		"sorter.json": `{"id": "test-sorter", "name": "Sorter", "colour": "#123456",
+			"script": "if type == red then out left else out right end", "inputs": ["back"]}`,
+		"broken.json": `{"id": "test-broken", "colour": "#123456", "script": "out"}`,
+		"both.json":   `{"id": "test-both", "colour": "#123456", "script": "out front", "behaviour": [{"op": "move"}]}`,
+	})
+	mods, err := LoadMods(dir)
+	if len(mods) != 1 {
+		t.Fatalf("Expected only the sorter to load, got %d mods", len(mods))
+	}
+	machine := mods[0].Spec.Machine
+	if got := machine.GetOutputs(); len(got) != 2 || got[0] != SideRight || got[1] != SideLeft {
+		t.Errorf("Expected the sorter to output right and left, got %v", got)
+	}
+	if got := machine.GetInputs(); len(got) != 1 || got[0] != SideBack {
+		t.Errorf("Expected the sorter to take input from the back, got %v", got)
+	}
+	for _, want := range []string{"broken.json: script: line 1: expected a value", "both.json: give either a behaviour or a script"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
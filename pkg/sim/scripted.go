@@ -0,0 +1,74 @@
+package sim
+
+import (
+	"math/rand"
+)
+
+// ScriptMachine is a machine loaded from a mod whose behaviour is a Script,
+// run once for each object on its cell.
+type ScriptMachine struct {
+	machineType MachineType
+	script      *Script
+	inputs      []Side
+	outputs     []Side
+	// errs are the failures of the script during the current run.
+	errs []error
+}
+
+// New returns a fresh scripted machine for a new placement.
+func (m *ScriptMachine) New() MachineInterface {
+	return &ScriptMachine{machineType: m.machineType, script: m.script, inputs: m.inputs, outputs: m.outputs}
+}
+
+// GetType returns the machine type.
+func (m *ScriptMachine) GetType() MachineType {
+	return m.machineType
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (m *ScriptMachine) GetInputs() []Side {
+	return m.inputs
+}
+
+// GetOutputs returns the sides the script sends objects out of.
+func (m *ScriptMachine) GetOutputs() []Side {
+	return m.outputs
+}
+
+// Process runs the script on each object on the machine's cell. An object
+// whose script fails or runs out of steps stays where it is this tick, and the
+// failure is reported at the end of the run.
+func (m *ScriptMachine) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	var changes []*Change
+	for _, obj := range current {
+		if obj.GridPosition != position {
+			continue
+		}
+		chs, err := m.script.Run(obj, position, history, tick, orientation, rng)
+		if err != nil {
+			m.errs = append(m.errs, &ScriptError{Err: err, Tick: tick, Position: position})
+			continue
+		}
+		changes = append(changes, chs...)
+	}
+	return changes
+}
+
+// takeErrors returns the script's failures so far and forgets them.
+func (m *ScriptMachine) takeErrors() []error {
+	errs := m.errs
+	m.errs = nil
+	return errs
+}
+
+// readsScores reports that scripts can look at an object's value and mults.
+func (m *ScriptMachine) readsScores() bool {
+	return true
+}
+
+// EmitEffects emits effects from the scripted machine.
+func (m *ScriptMachine) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// Scripts cannot emit effects
+	return nil
+}
@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Smelter represents a smelter machine. It is two cells wide.
+type Smelter struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "smelter",
+		Name:         "Smelter",
+		Description:  "A wide furnace that adds 2 value to objects passing through either of its cells, and refines metal one step along its chain.",
+		Roles:        []MachineRole{RoleMover, RoleUpgrader},
+		Color:        color.RGBA{R: 178, G: 34, B: 34, A: 255}, // Firebrick
+		Rarity:       RarityUncommon,
+		Cost:         6,
+		DefaultCount: 1,
+		Machine:      &Smelter{},
+	})
+}
+
+// New returns a fresh smelter for a new placement.
+func (s *Smelter) New() MachineInterface {
+	return &Smelter{}
+}
+
+// GetType returns the machine type.
+func (s *Smelter) GetType() MachineType {
+	return MachineSmelter
+}
+
+// GetFootprint returns the machine's size: two cells across its front and
+// one cell deep.
+func (s *Smelter) GetFootprint() (width, depth int) {
+	return 2, 1
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (s *Smelter) GetInputs() []Side {
+	return []Side{SideBack, SideLeft, SideRight}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (s *Smelter) GetOutputs() []Side {
+	return []Side{SideFront}
+}
+
+// Process handles object interaction for smelter. Each object in the smelter
+// goes straight out of the front of the cell it is on, and metal moves one
+// step along the metal chain.
+func (s *Smelter) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	var changes []*Change
+	for _, obj := range ObjectsOn(current, CoveredCells(position, s, orientation)) {
+		nextPos := GetAdjacentPosition(obj.GridPosition, orientation)
+		end := &Object{ID: obj.ID, GridPosition: nextPos, Type: obj.Type, Edition: obj.Edition, Score: &Score{Value: saturatingAdd(obj.Score.Value, 2), MultAdd: obj.Score.MultAdd, MultMult: obj.Score.MultMult}}
+		if next, ok := NextInChain("metal", obj.Type); ok {
+			end.ID = 0
+			end.ParentIDs = []int{obj.ID}
+			end.Type = next
+		}
+		changes = append(changes, &Change{
+			StartObject: obj,
+			EndObject:   end,
+			Score:       nil,
+		})
+	}
+	return changes
+}
+
+// EmitEffects emits effects from smelter.
+func (s *Smelter) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}
@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Splitter represents a splitter machine.
+type Splitter struct{}
+
+func init() {
+	Register(MachineSpec{
+		Key:          "splitter",
+		Name:         "Splitter",
+		Description:  "Takes one object and splits it into two objects of half the value, sending one out of each side.",
+		Roles:        []MachineRole{RoleMover, RoleProducer},
+		Color:        color.RGBA{R: 150, G: 150, B: 255, A: 255}, // Light blue
+		Rarity:       RarityUncommon,
+		Cost:         4,
+		DefaultCount: 1,
+		Machine:      &Splitter{},
+	})
+}
+
+// New returns a fresh splitter for a new placement.
+func (s *Splitter) New() MachineInterface {
+	return &Splitter{}
+}
+
+// GetType returns the machine type.
+func (s *Splitter) GetType() MachineType {
+	return MachineSplitter
+}
+
+// GetInputs returns the sides the machine accepts objects from.
+func (s *Splitter) GetInputs() []Side {
+	return []Side{SideBack}
+}
+
+// GetOutputs returns the sides the machine sends objects out of.
+func (s *Splitter) GetOutputs() []Side {
+	return []Side{SideLeft, SideRight}
+}
+
+// Process handles object interaction for splitter.
+func (s *Splitter) Process(position int, history [][]*Object, tick int, orientation Orientation, rng *rand.Rand, memory Memory) []*Change {
+	current := history[len(history)-1]
+	for _, obj := range current {
+		if obj.GridPosition == position {
+			leftPos := GetAdjacentPosition(position, SideLeft.Facing(orientation))
+			rightPos := GetAdjacentPosition(position, SideRight.Facing(orientation))
+			halfValue := obj.Score.Value / 2
+			if halfValue < 1 {
+				halfValue = 1 // Minimum value of 1
+			}
+			// Create two objects of half value, one out of each side
+			return []*Change{{
+				StartObject: obj,
+				EndObject:   &Object{ParentIDs: []int{obj.ID}, GridPosition: leftPos, Type: obj.Type, Edition: obj.Edition, Score: &Score{Value: halfValue, MultAdd: obj.Score.MultAdd, MultMult: obj.Score.MultMult}},
+				Score:       nil,
+			}, {
+				StartObject: obj,
+				EndObject:   &Object{ParentIDs: []int{obj.ID}, GridPosition: rightPos, Type: obj.Type, Edition: obj.Edition, Score: &Score{Value: halfValue, MultAdd: obj.Score.MultAdd, MultMult: obj.Score.MultMult}},
+				Score:       nil,
+			}}
+		}
+	}
+	return nil
+}
+
+// EmitEffects emits effects from splitter.
+func (s *Splitter) EmitEffects(position int, machines []*MachineState) []EffectEmission {
+	// For now, no effects
+	return nil
+}